@@ -0,0 +1,239 @@
+package catnip
+
+import (
+	"math"
+	"sort"
+
+	"github.com/noriah/catnip/dsp"
+	"github.com/noriah/catnip/dsp/window"
+	"github.com/noriah/catnip/fft"
+	"github.com/noriah/catnip/input"
+)
+
+// Analyzer analyzes blocks of samples into bars. An Analyzer is only used by
+// one goroutine at a time.
+type Analyzer interface {
+	// Recalculate lays out the given number of bars. It returns the number of
+	// bars that will actually be analyzed, which might be less.
+	Recalculate(bars int) int
+	// Analyze analyzes the last block of samples of each channel into the
+	// magnitude of each bar of the channel. Each channel of bars is as long
+	// as what Recalculate returned. The samples must not be modified.
+	Analyze(samples [][]input.Sample, bars [][]float64)
+}
+
+// FrequencyAnalyzer is an Analyzer whose bars are laid out along the
+// frequency axis. The grid can only label the frequencies of these.
+type FrequencyAnalyzer interface {
+	Analyzer
+	// BarPosition returns the fractional index of the bar that the given
+	// frequency falls on, where each bar is centered on its integer index.
+	// It must be safe to call concurrently with the other methods.
+	BarPosition(hz float64, bars int) float64
+}
+
+// newAnalyzer creates the analyzer of the config.
+func (cfg Config) newAnalyzer() Analyzer {
+	if cfg.NewAnalyzer != nil {
+		return cfg.NewAnalyzer(cfg)
+	}
+	return NewFFTAnalyzer(cfg)
+}
+
+// smoothingAnalyzer is an Analyzer that smooths the bars itself, so the
+// drawer doesn't smooth them again.
+type smoothingAnalyzer interface {
+	Analyzer
+	smoothsBars()
+}
+
+// blockGain returns the gain that scales the magnitudes of a block of the
+// given length up to those of a block of sampleSize samples. A sinusoid adds
+// up to a magnitude proportional to the length of the block, so analyzers that
+// analyze shorter blocks use it to keep the bars on the same scale as the
+// default analyzer.
+func blockGain(sampleSize, length int) float64 {
+	return float64(sampleSize) / float64(length)
+}
+
+// fftAnalyzer spreads the bins of an FFT over the bars.
+type fftAnalyzer struct {
+	spectrum spectrum
+	windowFn window.Function
+	// blockSize is the number of samples to window before the zero-padding.
+	blockSize int
+
+	plan   *fft.Plan
+	input  []float64
+	output []complex128
+}
+
+// NewFFTAnalyzer creates the default Analyzer, which spreads the bins of an
+// FFT over the bars along the FrequencyScale. If the frequency range, scale
+// and FFT size are left at their defaults, the bars keep their original
+// layout, as described by Config.MinFrequency.
+func NewFFTAnalyzer(cfg Config) Analyzer {
+	if cfg.MinFrequency == 0 && cfg.MaxFrequency == 0 &&
+		cfg.FrequencyScale == ScaleLogarithmic && cfg.fftSize() == cfg.SampleSize {
+		return newSpectrumAnalyzer(cfg)
+	}
+
+	a := &fftAnalyzer{
+		spectrum: spectrum{
+			SampleRate:   cfg.SampleRate,
+			SampleSize:   cfg.fftSize(),
+			BlockSize:    cfg.SampleSize,
+			Scale:        cfg.FrequencyScale,
+			MinFrequency: cfg.MinFrequency,
+			MaxFrequency: cfg.MaxFrequency,
+			Bins:         make([]spectrumBin, cfg.fftSize()),
+		},
+		windowFn:  cfg.WindowFn,
+		blockSize: cfg.SampleSize,
+		input:     make([]float64, cfg.fftSize()),
+		output:    make([]complex128, cfg.fftSize()/2+1),
+	}
+
+	a.plan = &fft.Plan{
+		Input:  a.input,
+		Output: a.output,
+	}
+	a.plan.Init()
+
+	return a
+}
+
+func (a *fftAnalyzer) Recalculate(bars int) int {
+	return a.spectrum.Recalculate(bars)
+}
+
+func (a *fftAnalyzer) Analyze(samples [][]input.Sample, bars [][]float64) {
+	for ch, buf := range bars {
+		// The window function works in place, so window a copy. The
+		// zero-padding after it is never written to.
+		copy(a.input, samples[ch])
+		a.windowFn(a.input[:a.blockSize])
+		a.plan.Execute()
+
+		for bar := range buf {
+			buf[bar] = a.spectrum.ProcessBin(bar, a.output)
+		}
+	}
+}
+
+func (a *fftAnalyzer) BarPosition(hz float64, bars int) float64 {
+	return a.spectrum.barPosition(hz, bars)
+}
+
+// spectrumAnalyzer analyzes the bars with dsp.Spectrum, which also smooths
+// them.
+type spectrumAnalyzer struct {
+	spectrum   dsp.Spectrum
+	windowFn   window.Function
+	sampleSize int
+
+	plan   *fft.Plan
+	input  []float64
+	output []complex128
+}
+
+func newSpectrumAnalyzer(cfg Config) *spectrumAnalyzer {
+	a := &spectrumAnalyzer{
+		spectrum: dsp.Spectrum{
+			SampleRate: cfg.SampleRate,
+			SampleSize: cfg.SampleSize,
+			Bins:       make([]dsp.Bin, cfg.SampleSize),
+		},
+		windowFn:   cfg.WindowFn,
+		sampleSize: cfg.SampleSize,
+		input:      make([]float64, cfg.SampleSize),
+		output:     make([]complex128, cfg.SampleSize/2+1),
+	}
+
+	a.spectrum.SetSmoothing(cfg.SmoothFactor / 100)
+
+	a.plan = &fft.Plan{
+		Input:  a.input,
+		Output: a.output,
+	}
+	a.plan.Init()
+
+	return a
+}
+
+func (a *spectrumAnalyzer) Recalculate(bars int) int {
+	return a.spectrum.Recalculate(bars)
+}
+
+func (a *spectrumAnalyzer) Analyze(samples [][]input.Sample, bars [][]float64) {
+	if len(a.spectrum.OldValues) != len(bars) {
+		a.spectrum.OldValues = allocBarBufs(a.sampleSize, len(bars))
+	}
+
+	for ch, buf := range bars {
+		copy(a.input, samples[ch])
+		a.windowFn(a.input)
+		a.plan.Execute()
+
+		for bar := range buf {
+			buf[bar] = a.spectrum.ProcessBin(ch, bar, a.output)
+		}
+	}
+}
+
+func (a *spectrumAnalyzer) BarPosition(hz float64, bars int) float64 {
+	if bars < 1 {
+		return math.NaN()
+	}
+
+	edges := spectrumEdges(a.spectrum.SampleRate, a.sampleSize, bars)
+	last := a.sampleSize / 2
+	end := func(bar int) int {
+		// dsp.Spectrum clamps where the bars end, but not where they start.
+		if edges[bar+1] > last {
+			return last
+		}
+		return edges[bar+1]
+	}
+
+	// Frequencies past either end are extrapolated from the outer bars.
+	pos := hz / (a.spectrum.SampleRate / float64(a.sampleSize))
+	bar := sort.Search(bars-1, func(i int) bool { return pos < float64(end(i)) })
+
+	width := end(bar) - edges[bar]
+	if width < 1 {
+		width = 1
+	}
+
+	return float64(bar) + (pos-float64(edges[bar]))/float64(width) - 0.5
+}
+
+func (a *spectrumAnalyzer) smoothsBars() {}
+
+// spectrumEdges returns the FFT bin that each of the bars of dsp.Spectrum
+// starts at, followed by the bin that the last bar ends at. dsp.Spectrum
+// doesn't export its bins, so this lays them out again the same way and has to
+// be kept in sync with it.
+func spectrumEdges(sampleRate float64, sampleSize, bars int) []int {
+	last := sampleSize / 2
+	res := sampleRate / float64(sampleSize)
+
+	loLog := math.Log10(dsp.Frequencies[1])
+	hiLog := math.Log10(math.Min(sampleRate/2, dsp.Frequencies[4]))
+	step := (hiLog - loLog) / float64(bars)
+
+	edges := make([]int, bars+1)
+	for i := range edges {
+		edge := int(math.Floor(math.Pow(10, float64(i)*step+loLog) / res))
+		if edge > last {
+			edge = last
+		}
+		// Every bar is at least one bin wide.
+		if i > 0 && edge <= edges[i-1] {
+			edge = edges[i-1] + 1
+		}
+		edges[i] = edge
+	}
+
+	return edges
+}
@@ -0,0 +1,50 @@
+package catnip
+
+import (
+	"math"
+	"testing"
+
+	"github.com/noriah/catnip/dsp/window"
+	"github.com/noriah/catnip/input"
+)
+
+func TestSpectrumAnalyzerBarPosition(t *testing.T) {
+	cfg := Config{
+		SampleRate: 48000,
+		SampleSize: 4096,
+		WindowFn:   window.Hann,
+	}
+
+	a := newSpectrumAnalyzer(cfg)
+	bars := a.Recalculate(48)
+
+	if pos := a.BarPosition(30, bars); pos >= -0.5 {
+		t.Errorf("30 Hz is at bar %g, want below the first bar", pos)
+	}
+	if pos := a.BarPosition(12000, bars); pos <= float64(bars)-0.5 {
+		t.Errorf("12000 Hz is at bar %g, want above the last bar", pos)
+	}
+
+	block := make([]input.Sample, cfg.SampleSize)
+	out := allocBarBufs(bars, 1)
+
+	for _, hz := range []float64{100, 440, 1000, 4000} {
+		for i := range block {
+			block[i] = math.Sin(2 * math.Pi * hz * float64(i) / cfg.SampleRate)
+		}
+
+		// SmoothFactor is 0, so the earlier blocks barely count.
+		a.Analyze([][]input.Sample{block}, out)
+
+		loudest := 0
+		for bar, mag := range out[0] {
+			if mag > out[0][loudest] {
+				loudest = bar
+			}
+		}
+
+		if pos := a.BarPosition(hz, bars); math.Abs(pos-float64(loudest)) > 1 {
+			t.Errorf("%g Hz is at bar %g, but bar %d is the loudest", hz, pos, loudest)
+		}
+	}
+}
@@ -26,10 +26,71 @@ type Config struct {
 	SmoothFactor float64
 	MinimumClamp float64 // height before visible
 
+	// HopSize is the number of samples that the input is recorded in. Every
+	// hop analyzes the last SampleSize samples, so hops shorter than
+	// SampleSize overlap the blocks, such as SampleSize/4 for 75%, and every
+	// block is smoothed into the bars. This lowers the latency to one hop
+	// without losing frequency resolution. 0 means SampleSize.
+	HopSize int
+	// BufferDepth is the number of SampleSize blocks of samples that can be
+	// queued for the analyzer before they're dropped. 0 means 4.
+	BufferDepth int
+	// FFTSize is the length of the FFT. Each block is zero-padded to it,
+	// which interpolates the spectrum without delaying it any further. It is
+	// at least SampleSize; 0 means SampleSize.
+	FFTSize int
+
+	// Interpolate tweens the bars between the last two analyzed blocks of
+	// samples instead of holding them until the next block arrives. This keeps
+	// the motion fluid at frame rates above SampleRate/HopSize, at the cost
+	// of delaying the bars by up to one hop.
+	Interpolate bool
+
+	// NewAnalyzer creates the Analyzer that analyzes the samples into bars.
+	// Nil means NewFFTAnalyzer.
+	NewAnalyzer func(Config) Analyzer
+	// Crossovers are the frequencies in Hz that NewMultiResolutionAnalyzer
+	// splits the bands at. Zero frequencies are ignored.
+	Crossovers []float64
+
+	// MinFrequency and MaxFrequency are the frequency range in Hz to spread
+	// the bars over along the FrequencyScale. Zero means the lowest and
+	// highest frequencies that the sample rate and size allow.
+	//
+	// If both are zero, the scale is ScaleLogarithmic and FFTSize is unset,
+	// the FFT analyzer keeps the original layout of the bars instead, which
+	// spreads them logarithmically from 60 Hz to 8 kHz and smooths them with
+	// its own curve. Any other scale uses the whole range.
+	MinFrequency   float64
+	MaxFrequency   float64
+	FrequencyScale FrequencyScale
+
 	DrawOptions
 
 	DrawStyle  DrawStyle
 	Monophonic bool
+
+	// Renderer overrides DrawStyle if it's not nil. Its bars are laid out
+	// like DrawVerticalBars, with the width split between the channels. The
+	// built-in renderers in Renderers are the same as setting their
+	// DrawStyle.
+	Renderer Renderer
+}
+
+// hopSize returns the hop size clamped to the sample size.
+func (cfg Config) hopSize() int {
+	if cfg.HopSize <= 0 || cfg.HopSize > cfg.SampleSize {
+		return cfg.SampleSize
+	}
+	return cfg.HopSize
+}
+
+// fftSize returns the FFT size, which is at least the sample size.
+func (cfg Config) fftSize() int {
+	if cfg.FFTSize < cfg.SampleSize {
+		return cfg.SampleSize
+	}
+	return cfg.FFTSize
 }
 
 // DrawStyle is the style to draw the bars symmetrically.
@@ -42,8 +103,47 @@ const (
 	DrawHorizontalBars
 	// DrawLines draws the spectrum as lines.
 	DrawLines
+	// DrawRadial draws the bars or lines around a circle mirrored if stereo.
+	DrawRadial
+	// DrawOscilloscope draws the raw samples as a waveform for each channel.
+	DrawOscilloscope
+	// DrawSpectrogram draws the history of the spectrum as a scrolling heat
+	// map. The channels are averaged.
+	DrawSpectrogram
+	// DrawVectorscope plots the left channel against the right channel, also
+	// known as a goniometer.
+	DrawVectorscope
 )
 
+// String returns the name of the style, which is also the name of its
+// built-in renderer.
+func (s DrawStyle) String() string {
+	switch s {
+	case DrawVerticalBars:
+		return "Vertical Bars"
+	case DrawHorizontalBars:
+		return "Horizontal Bars"
+	case DrawLines:
+		return "Lines"
+	case DrawRadial:
+		return "Radial"
+	case DrawOscilloscope:
+		return "Oscilloscope"
+	case DrawSpectrogram:
+		return "Spectrogram"
+	case DrawVectorscope:
+		return "Vectorscope"
+	default:
+		return fmt.Sprintf("DrawStyle(%d)", uint8(s))
+	}
+}
+
+// drawsSamples returns true if the style draws the raw samples instead of the
+// spectrum.
+func (s DrawStyle) drawsSamples() bool {
+	return s == DrawOscilloscope || s == DrawVectorscope
+}
+
 // WrapExternalWindowFn wraps external (mostly gonum/dsp/window) functions to be
 // compatible with catnip's usage. The implementation will assume that the given
 // function modifies the given slice in its place, which is the case for most
@@ -60,6 +160,13 @@ type DrawOptions struct {
 	AntiAlias cairo.Antialias
 
 	FrameRate int
+	// FrameClock drives the redraws with the widget's frame clock instead of
+	// a timer at FrameRate, which keeps them in sync with the monitor. The
+	// redraws stop while the widget is unmapped.
+	FrameClock bool
+	// MaxFrameRate caps the frame rate of FrameClock; 0 to redraw on every
+	// frame of the monitor.
+	MaxFrameRate int
 
 	Colors     Colors
 	Offsets    DrawOffsets
@@ -69,8 +176,130 @@ type DrawOptions struct {
 	// ForceEven will round the width and height to be even. This will force
 	// Cairo to always draw the bars sharply.
 	ForceEven bool
+
+	// Radial is only used for DrawRadial.
+	Radial RadialOptions
+	// Oscilloscope is only used for DrawOscilloscope.
+	Oscilloscope OscilloscopeOptions
+	// Spectrogram is only used for DrawSpectrogram.
+	Spectrogram SpectrogramOptions
+	// Vectorscope is only used for DrawVectorscope.
+	Vectorscope VectorscopeOptions
+	// PeakCaps is only used for DrawVerticalBars and DrawHorizontalBars.
+	PeakCaps PeakCapOptions
+	// Segments is only used for DrawVerticalBars and DrawHorizontalBars.
+	Segments SegmentOptions
+	// Grid is the overlay of reference lines and labels.
+	Grid GridOptions
+	// Glow and Shadow are the blurred effects drawn under the visualizer.
+	Glow   GlowOptions
+	Shadow ShadowOptions
+
+	// Persistence is the ratio of the previous frame to keep under the new
+	// one, from 0 to 1. This draws phosphor-like trails; 0 to disable, or to
+	// use VectorscopeOptions.Persistence for DrawVectorscope.
+	Persistence float64
+
+	ColorMode ColorMode
+
+	// Orientation is the direction that the bars grow towards.
+	Orientation Orientation
+	// ReverseFrequency draws the high frequencies first.
+	ReverseFrequency bool
+}
+
+// Orientation is the direction that the bars grow towards. The frequency axis
+// is perpendicular to it.
+type Orientation uint8
+
+const (
+	// OrientBottomUp grows the bars from the bottom with the frequencies going
+	// from the left to the right.
+	OrientBottomUp Orientation = iota
+	// OrientTopDown grows the bars from the top with the frequencies going
+	// from the left to the right.
+	OrientTopDown
+	// OrientLeftToRight grows the bars from the left with the frequencies
+	// going from the bottom to the top.
+	OrientLeftToRight
+	// OrientRightToLeft grows the bars from the right with the frequencies
+	// going from the bottom to the top.
+	OrientRightToLeft
+)
+
+// orient transforms the given context so that everything can be drawn as if
+// the orientation is OrientBottomUp with the frequencies going from the left
+// to the right. It returns the width and height of the transformed space.
+func (opts DrawOptions) orient(cr *cairo.Context, width, height float64) (float64, float64) {
+	switch opts.Orientation {
+	case OrientTopDown:
+		cr.Translate(0, height)
+		cr.Scale(1, -1)
+	case OrientLeftToRight:
+		// Map (x, y) to (width-y, height-x).
+		cr.Translate(width, height)
+		cr.Rotate(math.Pi / 2)
+		cr.Scale(-1, 1)
+	case OrientRightToLeft:
+		// Map (x, y) to (y, height-x).
+		cr.Translate(0, height)
+		cr.Rotate(-math.Pi / 2)
+	}
+
+	if opts.Orientation.swapsAxes() {
+		width, height = height, width
+	}
+
+	if opts.ReverseFrequency {
+		cr.Translate(width, 0)
+		cr.Scale(-1, 1)
+	}
+
+	return width, height
+}
+
+// unorient maps the given point within the transformed space of orient back
+// to the untransformed space of the given width and height.
+func (opts DrawOptions) unorient(x, y, width, height float64) (float64, float64) {
+	if opts.ReverseFrequency {
+		if opts.Orientation.swapsAxes() {
+			x = height - x
+		} else {
+			x = width - x
+		}
+	}
+
+	switch opts.Orientation {
+	case OrientTopDown:
+		return x, height - y
+	case OrientLeftToRight:
+		return width - y, height - x
+	case OrientRightToLeft:
+		return y, height - x
+	default:
+		return x, y
+	}
 }
 
+// swapsAxes returns true if the frequency axis is vertical.
+func (o Orientation) swapsAxes() bool {
+	return o == OrientLeftToRight || o == OrientRightToLeft
+}
+
+// ColorMode is the mode to color the bars with.
+type ColorMode uint8
+
+const (
+	// ColorStatic draws all bars with the foreground color or gradient.
+	ColorStatic ColorMode = iota
+	// ColorAmplitude colors each bar by interpolating between the Quiet and
+	// Loud colors with its current value.
+	ColorAmplitude
+	// ColorLoudness colors the whole frame by interpolating between the Quiet
+	// and Loud colors with the overall loudness.
+	ColorLoudness
+)
+
 func (opts DrawOptions) even(n int) int {
 	if !opts.ForceEven {
 		return n
@@ -85,6 +314,13 @@ func (opts DrawOptions) round(f float64) float64 {
 	return math.Round(f)
 }
 
+// binWidth returns the total width of a bar and the space around it.
+func (opts DrawOptions) binWidth() float64 {
+	// Weird Cairo tricks require multiplication and division by 2. Unsure
+	// why.
+	return opts.BarWidth + (opts.SpaceWidth * 2)
+}
+
 // DrawOffsets controls the offset for the Drawer.
 type DrawOffsets struct {
 	X, Y float64
@@ -95,10 +331,196 @@ func (offset DrawOffsets) apply(x, y float64) (float64, float64) {
 	return x + offset.X, y + offset.Y
 }
 
+// RadialOptions controls the circle that DrawRadial draws around.
+type RadialOptions struct {
+	InnerRadius float64 // ratio of the outer radius, from 0 to 1
+	StartAngle  float64 // degrees clockwise from the top
+	Sweep       float64 // degrees, 360 for a full circle
+	// Lines draws a smoothed line instead of bars. The line is closed if Sweep
+	// is a full circle.
+	Lines bool
+}
+
+// ring calculates the center and the inner and outer radii of the circle
+// within the given dimensions.
+func (opts RadialOptions) ring(width, height float64) (cx, cy, inner, outer float64) {
+	cx = width / 2
+	cy = height / 2
+	outer = math.Min(width, height) / 2
+	inner = outer * math.Max(math.Min(opts.InnerRadius, 1), 0)
+	return
+}
+
+// arcLength calculates the length of the arc in the middle of the ring. Bars
+// are laid out along this arc.
+func (opts RadialOptions) arcLength(width, height float64) float64 {
+	_, _, inner, outer := opts.ring(width, height)
+	return (inner + outer) / 2 * (opts.Sweep * math.Pi / 180)
+}
+
+// full returns true if the sweep covers the whole circle.
+func (opts RadialOptions) full() bool {
+	return opts.Sweep >= 360
+}
+
+// OscilloscopeOptions controls how DrawOscilloscope draws the waveform.
+type OscilloscopeOptions struct {
+	// Gain multiplies the samples before drawing. A sample of 1 reaches the
+	// edge of its channel at a gain of 1.
+	Gain float64
+}
+
+// SpectrogramOptions controls how DrawSpectrogram draws the heat map.
+type SpectrogramOptions struct {
+	// History is the number of frames to keep. The spectrogram scrolls by one
+	// frame every redraw.
+	History  int
+	ColorMap ColorMap
+	// Gradient is the evenly spaced colors used for ColorMapCustom.
+	Gradient []color.Color
+}
+
+// VectorscopeOptions controls how DrawVectorscope plots the channels.
+type VectorscopeOptions struct {
+	// Gain multiplies the samples before plotting. A sample of 1 reaches the
+	// edge of the circle at a gain of 1.
+	Gain float64
+	// MidSide rotates the plot by 45°, so that mono signals are drawn
+	// vertically and out-of-phase signals horizontally. The plot is best
+	// drawn with some Persistence.
+	MidSide bool
+	// Persistence is used instead of DrawOptions.Persistence if that is 0,
+	// so that the plot fades between frames without trailing the other
+	// styles.
+	Persistence float64
+}
+
+// PeakCapOptions controls the markers that hold the recent maximum of each
+// bar and then fall down.
+type PeakCapOptions struct {
+	Thickness float64 // 0 to disable
+	HoldTime  float64 // seconds
+	Gravity   float64 // heights per second squared
+}
+
+// SegmentOptions controls how bars are split into discrete segments, like an
+// LED meter.
+type SegmentOptions struct {
+	Height float64 // 0 to disable
+	Gap    float64
+	// WarningLevel and CriticalLevel are the ratios of the maximum bar length
+	// that the segments start using the Warning and Critical colors at.
+	WarningLevel  float64
+	CriticalLevel float64
+}
+
+func (opts SegmentOptions) enabled() bool {
+	return opts.Height > 0
+}
+
+// zone returns the zone of the segment at the given level: 0 is normal, 1 is
+// warning and 2 is critical.
+func (opts SegmentOptions) zone(level float64) int {
+	switch {
+	case level > opts.CriticalLevel:
+		return 2
+	case level > opts.WarningLevel:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GridOptions controls the overlay of reference lines and labels, which is
+// drawn over the visualizer.
+type GridOptions struct {
+	// Frequencies draws a line and a label at the common frequencies within
+	// the frequency range. It is only drawn for DrawVerticalBars,
+	// DrawHorizontalBars, DrawLines and DrawSpectrogram, and never for custom
+	// renderers. It is also only drawn for a FrequencyAnalyzer.
+	Frequencies bool
+	// DecibelStep is the distance between the amplitude lines in dB, counting
+	// down from 0 dB at the current scale. 0 to disable. It is only drawn for
+	// DrawVerticalBars, DrawHorizontalBars and DrawLines, and never for custom
+	// renderers.
+	DecibelStep float64
+}
+
+func (opts GridOptions) enabled() bool {
+	return opts.Frequencies || opts.DecibelStep > 0
+}
+
+// GlowOptions controls the blurred glow drawn under the visualizer.
+type GlowOptions struct {
+	Radius float64 // 0 to disable
+	// Intensity multiplies the alpha of the glow. Intensities above 1 draw
+	// the glow more than once.
+	Intensity float64
+}
+
+func (opts GlowOptions) enabled() bool {
+	return opts.Radius > 0 && opts.Intensity > 0
+}
+
+// ShadowOptions controls the blurred drop shadow drawn under the visualizer.
+type ShadowOptions struct {
+	OffsetX float64
+	OffsetY float64
+	Blur    float64
+}
+
+func (opts ShadowOptions) enabled() bool {
+	return opts.OffsetX != 0 || opts.OffsetY != 0 || opts.Blur > 0
+}
+
 // Colors is the color settings for the Drawer.
 type Colors struct {
 	Foreground color.Color // use Gtk if nil
 	Background color.Color // transparent if nil
+	Peak       color.Color // use Foreground if nil
+	// Warning and Critical are the colors of the segments in their level
+	// zones. The zones use Foreground if nil.
+	Warning  color.Color
+	Critical color.Color
+	// Gradient overrides Foreground if it's not nil and has stops.
+	Gradient *Gradient
+	// Quiet and Loud are the colors used by the amplitude color modes. Quiet
+	// defaults to a dim Foreground, and Loud defaults to Foreground.
+	Quiet color.Color
+	Loud  color.Color
+	// Grid is the color of the grid overlay. It defaults to a dim Gtk
+	// foreground.
+	Grid color.Color
+	// Glow defaults to Foreground, and Shadow defaults to a translucent
+	// black.
+	Glow   color.Color
+	Shadow color.Color
+}
+
+// GradientKind is the shape of a Gradient.
+type GradientKind uint8
+
+const (
+	// GradientFrequency is a linear gradient along the frequency axis, which
+	// goes from the left to the right.
+	GradientFrequency GradientKind = iota
+	// GradientAmplitude is a linear gradient along the amplitude axis, which
+	// goes from the base of the bars to their maximum length.
+	GradientAmplitude
+	// GradientRadial is a radial gradient from the center outwards.
+	GradientRadial
+)
+
+// Gradient is a foreground gradient with an arbitrary number of color stops.
+type Gradient struct {
+	Kind  GradientKind
+	Stops []GradientStop
+}
+
+// GradientStop is a color stop within a gradient.
+type GradientStop struct {
+	Offset float64 // from 0 to 1
+	Color  color.Color
 }
 
 // ScalingConfig is the scaling settings for the visualizer.
@@ -131,6 +553,33 @@ func NewConfig() Config {
 			FrameRate:  60, // 60fps
 			BarWidth:   10,
 			SpaceWidth: 5,
+			Radial: RadialOptions{
+				InnerRadius: 0.5,
+				Sweep:       360,
+			},
+			Oscilloscope: OscilloscopeOptions{
+				Gain: 1,
+			},
+			Spectrogram: SpectrogramOptions{
+				History: 300, // 5s at 60fps
+			},
+			Vectorscope: VectorscopeOptions{
+				Gain:        1,
+				MidSide:     true,
+				Persistence: 0.75,
+			},
+			PeakCaps: PeakCapOptions{
+				HoldTime: 0.5,
+				Gravity:  2,
+			},
+			Segments: SegmentOptions{
+				Gap:           2,
+				WarningLevel:  0.6,
+				CriticalLevel: 0.85,
+			},
+			Glow: GlowOptions{
+				Intensity: 1,
+			},
 		},
 
 		Scaling: ScalingConfig{
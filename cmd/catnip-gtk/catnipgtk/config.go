@@ -2,6 +2,7 @@ package catnipgtk
 
 import (
 	"encoding/json"
+	"image/color"
 	"os"
 	"path/filepath"
 
@@ -101,17 +102,80 @@ func (cfg Config) Transform() catnip.Config {
 		WindowFn:     cfg.Visualizer.WindowFn.AsFunction(),
 		SampleRate:   cfg.Visualizer.SampleRate,
 		SampleSize:   cfg.Visualizer.SampleSize,
+		HopSize:      cfg.Visualizer.HopSize(),
+		FFTSize:      cfg.Visualizer.FFTSize,
 		SmoothFactor: cfg.Visualizer.SmoothFactor,
+		Interpolate:  cfg.Visualizer.Interpolate,
+		BufferDepth:  cfg.Visualizer.BufferDepth,
 		MinimumClamp: cfg.Appearance.MinimumClamp,
 		DrawStyle:    cfg.Appearance.DrawStyle,
+		Renderer:     catnip.FindRenderer(cfg.Appearance.RendererName()),
+
+		MinFrequency:   cfg.Visualizer.MinFrequency,
+		MaxFrequency:   cfg.Visualizer.MaxFrequency,
+		FrequencyScale: cfg.Visualizer.FrequencyScale.AsFrequencyScale(),
+		NewAnalyzer:    cfg.Visualizer.Analyzer.AsNewAnalyzer(),
+		Crossovers: []float64{
+			cfg.Visualizer.BassCrossover,
+			cfg.Visualizer.TrebleCrossover,
+		},
+
 		DrawOptions: catnip.DrawOptions{
-			LineCap:    cfg.Appearance.LineCap.AsLineCap(),
-			LineJoin:   cairo.LINE_JOIN_MITER,
-			FrameRate:  cfg.Visualizer.FrameRate,
-			BarWidth:   cfg.Appearance.BarWidth,
-			SpaceWidth: cfg.Appearance.SpaceWidth,
-			AntiAlias:  cfg.Appearance.AntiAlias.AsAntialias(),
-			ForceEven:  false,
+			LineCap:      cfg.Appearance.LineCap.AsLineCap(),
+			LineJoin:     cairo.LINE_JOIN_MITER,
+			FrameRate:    cfg.Visualizer.FrameRate,
+			FrameClock:   cfg.Visualizer.FrameClock,
+			MaxFrameRate: cfg.Visualizer.MaxFrameRate,
+			BarWidth:     cfg.Appearance.BarWidth,
+			SpaceWidth:   cfg.Appearance.SpaceWidth,
+			AntiAlias:    cfg.Appearance.AntiAlias.AsAntialias(),
+			ForceEven:    false,
+			Radial: catnip.RadialOptions{
+				InnerRadius: cfg.Appearance.RadialInnerRadius,
+				StartAngle:  cfg.Appearance.RadialStartAngle,
+				Sweep:       cfg.Appearance.RadialSweep,
+				Lines:       cfg.Appearance.RadialLines,
+			},
+			Oscilloscope: catnip.OscilloscopeOptions{
+				Gain: cfg.Appearance.OscilloscopeGain,
+			},
+			Spectrogram: catnip.SpectrogramOptions{
+				History:  cfg.Appearance.SpectrogramHistory,
+				ColorMap: cfg.Appearance.SpectrogramColorMap.AsColorMap(),
+			},
+			Vectorscope: catnip.VectorscopeOptions{
+				Gain:        cfg.Appearance.VectorscopeGain,
+				MidSide:     cfg.Appearance.VectorscopeMidSide,
+				Persistence: cfg.Appearance.VectorscopePersistence,
+			},
+			PeakCaps: catnip.PeakCapOptions{
+				Thickness: cfg.Appearance.PeakThickness,
+				HoldTime:  cfg.Appearance.PeakHoldTime,
+				Gravity:   cfg.Appearance.PeakGravity,
+			},
+			Segments: catnip.SegmentOptions{
+				Height:        cfg.Appearance.SegmentHeight,
+				Gap:           cfg.Appearance.SegmentGap,
+				WarningLevel:  cfg.Appearance.SegmentWarningLevel,
+				CriticalLevel: cfg.Appearance.SegmentCriticalLevel,
+			},
+			Grid: catnip.GridOptions{
+				Frequencies: cfg.Appearance.GridFrequencies,
+				DecibelStep: cfg.Appearance.GridDecibelStep,
+			},
+			Glow: catnip.GlowOptions{
+				Radius:    cfg.Appearance.GlowRadius,
+				Intensity: cfg.Appearance.GlowIntensity,
+			},
+			Shadow: catnip.ShadowOptions{
+				OffsetX: cfg.Appearance.ShadowOffsetX,
+				OffsetY: cfg.Appearance.ShadowOffsetY,
+				Blur:    cfg.Appearance.ShadowBlur,
+			},
+			Persistence:      cfg.Appearance.Persistence,
+			ColorMode:        cfg.Appearance.ColorMode.AsColorMode(),
+			Orientation:      cfg.Appearance.Orientation.AsOrientation(),
+			ReverseFrequency: cfg.Appearance.ReverseFrequency,
 		},
 		Scaling: catnip.ScalingConfig{
 			SlowWindow:     5,
@@ -127,6 +191,47 @@ func (cfg Config) Transform() catnip.Config {
 	if cfg.Appearance.BackgroundColor != nil {
 		catnipCfg.DrawOptions.Colors.Background = cfg.Appearance.BackgroundColor
 	}
+	if gradient := cfg.Appearance.Gradient.AsGradient(); gradient != nil {
+		catnipCfg.DrawOptions.Colors.Gradient = gradient
+	}
+	if cfg.Appearance.QuietColor != nil {
+		catnipCfg.DrawOptions.Colors.Quiet = cfg.Appearance.QuietColor
+	}
+	if cfg.Appearance.LoudColor != nil {
+		catnipCfg.DrawOptions.Colors.Loud = cfg.Appearance.LoudColor
+	}
+	if cfg.Appearance.PeakColor != nil {
+		catnipCfg.DrawOptions.Colors.Peak = cfg.Appearance.PeakColor
+	}
+	if cfg.Appearance.SegmentWarningColor != nil {
+		catnipCfg.DrawOptions.Colors.Warning = cfg.Appearance.SegmentWarningColor
+	}
+	if cfg.Appearance.SegmentCriticalColor != nil {
+		catnipCfg.DrawOptions.Colors.Critical = cfg.Appearance.SegmentCriticalColor
+	}
+	if cfg.Appearance.GridColor != nil {
+		catnipCfg.DrawOptions.Colors.Grid = cfg.Appearance.GridColor
+	}
+	if cfg.Appearance.GlowColor != nil {
+		catnipCfg.DrawOptions.Colors.Glow = cfg.Appearance.GlowColor
+	}
+	if cfg.Appearance.ShadowColor != nil {
+		catnipCfg.DrawOptions.Colors.Shadow = cfg.Appearance.ShadowColor
+	}
+
+	if low, high := cfg.Appearance.SpectrogramLowColor, cfg.Appearance.SpectrogramHighColor; low != nil || high != nil {
+		// Default to transparent for silence and the foreground color for the
+		// loudest bars, the same as the reset values of the color buttons.
+		gradient := []color.Color{catnip.CairoColor{}, nil}
+		if low != nil {
+			gradient[0] = low
+		}
+		if high != nil {
+			gradient[1] = high
+		}
+
+		catnipCfg.DrawOptions.Spectrogram.Gradient = gradient
+	}
 
 	return catnipCfg
 }
@@ -15,28 +15,65 @@ type Appearance struct {
 
 	ForegroundColor OptionalColor
 	BackgroundColor OptionalColor
+	Gradient        Gradient
+	ColorMode       ColorMode
+	QuietColor      OptionalColor
+	LoudColor       OptionalColor
 
 	BarWidth     float64
 	SpaceWidth   float64 // gap width
 	MinimumClamp float64
 	AntiAlias    AntiAlias
 
-	DrawStyle catnip.DrawStyle
+	// DrawStyle is only used if Renderer is empty, which is the case for
+	// older configs.
+	DrawStyle        catnip.DrawStyle
+	Renderer         string
+	Orientation      Orientation
+	ReverseFrequency bool
 
-	CustomCSS string
-}
+	RadialInnerRadius float64
+	RadialStartAngle  float64
+	RadialSweep       float64
+	RadialLines       bool
 
-func symmetryString(s catnip.DrawStyle) string {
-	switch s {
-	case catnip.DrawVerticalBars:
-		return "Vertical Bars"
-	case catnip.DrawHorizontalBars:
-		return "Horizontal Bars"
-	case catnip.DrawLines:
-		return "Lines"
-	default:
-		return ""
-	}
+	OscilloscopeGain float64
+
+	SpectrogramHistory   int
+	SpectrogramColorMap  ColorMap
+	SpectrogramLowColor  OptionalColor
+	SpectrogramHighColor OptionalColor
+
+	VectorscopeGain        float64
+	VectorscopeMidSide     bool
+	VectorscopePersistence float64
+
+	PeakThickness float64
+	PeakHoldTime  float64
+	PeakGravity   float64
+	PeakColor     OptionalColor
+
+	SegmentHeight        float64
+	SegmentGap           float64
+	SegmentWarningLevel  float64
+	SegmentCriticalLevel float64
+	SegmentWarningColor  OptionalColor
+	SegmentCriticalColor OptionalColor
+
+	GridFrequencies bool
+	GridDecibelStep float64
+	GridColor       OptionalColor
+
+	GlowRadius    float64
+	GlowIntensity float64
+	GlowColor     OptionalColor
+	ShadowOffsetX float64
+	ShadowOffsetY float64
+	ShadowBlur    float64
+	ShadowColor   OptionalColor
+	Persistence   float64
+
+	CustomCSS string
 }
 
 func NewAppearance() Appearance {
@@ -46,6 +83,30 @@ func NewAppearance() Appearance {
 		SpaceWidth:   1,
 		MinimumClamp: 1,
 		AntiAlias:    AntiAliasGood,
+		Gradient:     Gradient{Kind: GradientNone},
+		ColorMode:    ColorStatic,
+		Orientation:  OrientBottomUp,
+
+		RadialInnerRadius: 0.5,
+		RadialSweep:       360,
+
+		OscilloscopeGain: 1,
+
+		SpectrogramHistory:  300,
+		SpectrogramColorMap: ColorMapViridis,
+
+		VectorscopeGain:        1,
+		VectorscopeMidSide:     true,
+		VectorscopePersistence: 0.75,
+
+		PeakHoldTime: 0.5,
+		PeakGravity:  2,
+
+		SegmentGap:           2,
+		SegmentWarningLevel:  0.6,
+		SegmentCriticalLevel: 0.85,
+
+		GlowIntensity: 1,
 	}
 }
 
@@ -144,13 +205,13 @@ func (ac *Appearance) Page(apply func()) *handy.PreferencesPage {
 
 	styleCombo := gtk.NewComboBoxText()
 	styleCombo.SetVAlign(gtk.AlignCenter)
-	styleCombo.AppendText(symmetryString(catnip.DrawVerticalBars))
-	styleCombo.AppendText(symmetryString(catnip.DrawHorizontalBars))
-	styleCombo.AppendText(symmetryString(catnip.DrawLines))
-	styleCombo.SetActive(int(ac.DrawStyle))
+	for _, renderer := range catnip.Renderers {
+		styleCombo.Append(renderer.Name, renderer.Name)
+	}
+	styleCombo.SetActiveID(ac.RendererName())
 	styleCombo.Show()
-	styleCombo.Connect("changed", func(symmCombo *gtk.ComboBoxText) {
-		ac.DrawStyle = catnip.DrawStyle(symmCombo.Active())
+	styleCombo.Connect("changed", func(styleCombo *gtk.ComboBoxText) {
+		ac.Renderer = styleCombo.ActiveID()
 		apply()
 	})
 
@@ -158,9 +219,42 @@ func (ac *Appearance) Page(apply func()) *handy.PreferencesPage {
 	styleRow.Add(styleCombo)
 	styleRow.SetActivatableWidget(styleCombo)
 	styleRow.SetTitle("DrawStyle")
-	styleRow.SetSubtitle("Whether to mirror bars vertically or horizontally.")
+	styleRow.SetSubtitle("The style of the visualization to draw.")
 	styleRow.Show()
 
+	orientationCombo := gtk.NewComboBoxText()
+	orientationCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(orientationCombo, OrientBottomUp, OrientTopDown, OrientLeftToRight, OrientRightToLeft)
+	orientationCombo.SetActiveID(string(ac.Orientation))
+	orientationCombo.Show()
+	orientationCombo.Connect("changed", func(orientationCombo *gtk.ComboBoxText) {
+		ac.Orientation = Orientation(orientationCombo.ActiveID())
+		apply()
+	})
+
+	orientationRow := handy.NewActionRow()
+	orientationRow.Add(orientationCombo)
+	orientationRow.SetActivatableWidget(orientationCombo)
+	orientationRow.SetTitle("Orientation")
+	orientationRow.SetSubtitle("The direction that the bars grow towards.")
+	orientationRow.Show()
+
+	reverseFreq := gtk.NewSwitch()
+	reverseFreq.SetVAlign(gtk.AlignCenter)
+	reverseFreq.SetActive(ac.ReverseFrequency)
+	reverseFreq.Show()
+	reverseFreq.Connect("state-set", func(reverseFreq *gtk.Switch, state bool) {
+		ac.ReverseFrequency = state
+		apply()
+	})
+
+	reverseFreqRow := handy.NewActionRow()
+	reverseFreqRow.Add(reverseFreq)
+	reverseFreqRow.SetActivatableWidget(reverseFreq)
+	reverseFreqRow.SetTitle("Reverse Frequencies")
+	reverseFreqRow.SetSubtitle("If enabled, will draw the high frequencies first.")
+	reverseFreqRow.Show()
+
 	barGroup := handy.NewPreferencesGroup()
 	barGroup.SetTitle("Bars")
 	barGroup.Add(lineCapRow)
@@ -169,8 +263,368 @@ func (ac *Appearance) Page(apply func()) *handy.PreferencesPage {
 	barGroup.Add(clampRow)
 	barGroup.Add(aaRow)
 	barGroup.Add(styleRow)
+	barGroup.Add(orientationRow)
+	barGroup.Add(reverseFreqRow)
 	barGroup.Show()
 
+	peakThicknessSpin := gtk.NewSpinButtonWithRange(0, 25, 1)
+	peakThicknessSpin.SetVAlign(gtk.AlignCenter)
+	peakThicknessSpin.SetDigits(1)
+	peakThicknessSpin.SetValue(ac.PeakThickness)
+	peakThicknessSpin.Show()
+	peakThicknessSpin.Connect("value-changed", func(peakThicknessSpin *gtk.SpinButton) {
+		ac.PeakThickness = peakThicknessSpin.Value()
+		apply()
+	})
+
+	peakThicknessRow := handy.NewActionRow()
+	peakThicknessRow.Add(peakThicknessSpin)
+	peakThicknessRow.SetActivatableWidget(peakThicknessSpin)
+	peakThicknessRow.SetTitle("Thickness")
+	peakThicknessRow.SetSubtitle("The thickness of the peak caps; 0 to disable them.")
+	peakThicknessRow.Show()
+
+	peakHoldSpin := gtk.NewSpinButtonWithRange(0, 10, 0.1)
+	peakHoldSpin.SetVAlign(gtk.AlignCenter)
+	peakHoldSpin.SetDigits(1)
+	peakHoldSpin.SetValue(ac.PeakHoldTime)
+	peakHoldSpin.Show()
+	peakHoldSpin.Connect("value-changed", func(peakHoldSpin *gtk.SpinButton) {
+		ac.PeakHoldTime = peakHoldSpin.Value()
+		apply()
+	})
+
+	peakHoldRow := handy.NewActionRow()
+	peakHoldRow.Add(peakHoldSpin)
+	peakHoldRow.SetActivatableWidget(peakHoldSpin)
+	peakHoldRow.SetTitle("Hold Time (s)")
+	peakHoldRow.SetSubtitle("How long the peak caps stay before falling.")
+	peakHoldRow.Show()
+
+	peakGravitySpin := gtk.NewSpinButtonWithRange(0.1, 50, 0.1)
+	peakGravitySpin.SetVAlign(gtk.AlignCenter)
+	peakGravitySpin.SetDigits(1)
+	peakGravitySpin.SetValue(ac.PeakGravity)
+	peakGravitySpin.Show()
+	peakGravitySpin.Connect("value-changed", func(peakGravitySpin *gtk.SpinButton) {
+		ac.PeakGravity = peakGravitySpin.Value()
+		apply()
+	})
+
+	peakGravityRow := handy.NewActionRow()
+	peakGravityRow.Add(peakGravitySpin)
+	peakGravityRow.SetActivatableWidget(peakGravitySpin)
+	peakGravityRow.SetTitle("Gravity")
+	peakGravityRow.SetSubtitle("How fast the peak caps fall in heights per second squared.")
+	peakGravityRow.Show()
+
+	peakColorRow := newColorRow(&ac.PeakColor, true, apply)
+	peakColorRow.SetTitle("Color")
+	peakColorRow.SetSubtitle("The color of the peak caps.")
+	peakColorRow.Show()
+
+	peakGroup := handy.NewPreferencesGroup()
+	peakGroup.SetTitle("Peak Caps")
+	peakGroup.Add(peakThicknessRow)
+	peakGroup.Add(peakHoldRow)
+	peakGroup.Add(peakGravityRow)
+	peakGroup.Add(peakColorRow)
+	peakGroup.Show()
+
+	segmentHeightSpin := gtk.NewSpinButtonWithRange(0, 50, 1)
+	segmentHeightSpin.SetVAlign(gtk.AlignCenter)
+	segmentHeightSpin.SetDigits(1)
+	segmentHeightSpin.SetValue(ac.SegmentHeight)
+	segmentHeightSpin.Show()
+	segmentHeightSpin.Connect("value-changed", func(segmentHeightSpin *gtk.SpinButton) {
+		ac.SegmentHeight = segmentHeightSpin.Value()
+		apply()
+	})
+
+	segmentHeightRow := handy.NewActionRow()
+	segmentHeightRow.Add(segmentHeightSpin)
+	segmentHeightRow.SetActivatableWidget(segmentHeightSpin)
+	segmentHeightRow.SetTitle("Segment Height")
+	segmentHeightRow.SetSubtitle("The height of each segment; 0 to draw solid bars.")
+	segmentHeightRow.Show()
+
+	segmentGapSpin := gtk.NewSpinButtonWithRange(0, 50, 1)
+	segmentGapSpin.SetVAlign(gtk.AlignCenter)
+	segmentGapSpin.SetDigits(1)
+	segmentGapSpin.SetValue(ac.SegmentGap)
+	segmentGapSpin.Show()
+	segmentGapSpin.Connect("value-changed", func(segmentGapSpin *gtk.SpinButton) {
+		ac.SegmentGap = segmentGapSpin.Value()
+		apply()
+	})
+
+	segmentGapRow := handy.NewActionRow()
+	segmentGapRow.Add(segmentGapSpin)
+	segmentGapRow.SetActivatableWidget(segmentGapSpin)
+	segmentGapRow.SetTitle("Segment Gap")
+	segmentGapRow.SetSubtitle("The gap between each segment.")
+	segmentGapRow.Show()
+
+	warningLevelSpin := gtk.NewSpinButtonWithRange(0, 1, 0.05)
+	warningLevelSpin.SetVAlign(gtk.AlignCenter)
+	warningLevelSpin.SetDigits(2)
+	warningLevelSpin.SetValue(ac.SegmentWarningLevel)
+	warningLevelSpin.Show()
+	warningLevelSpin.Connect("value-changed", func(warningLevelSpin *gtk.SpinButton) {
+		ac.SegmentWarningLevel = warningLevelSpin.Value()
+		apply()
+	})
+
+	warningLevelRow := handy.NewActionRow()
+	warningLevelRow.Add(warningLevelSpin)
+	warningLevelRow.SetActivatableWidget(warningLevelSpin)
+	warningLevelRow.SetTitle("Warning Level")
+	warningLevelRow.SetSubtitle("The ratio of the height that segments start using the warning color at.")
+	warningLevelRow.Show()
+
+	criticalLevelSpin := gtk.NewSpinButtonWithRange(0, 1, 0.05)
+	criticalLevelSpin.SetVAlign(gtk.AlignCenter)
+	criticalLevelSpin.SetDigits(2)
+	criticalLevelSpin.SetValue(ac.SegmentCriticalLevel)
+	criticalLevelSpin.Show()
+	criticalLevelSpin.Connect("value-changed", func(criticalLevelSpin *gtk.SpinButton) {
+		ac.SegmentCriticalLevel = criticalLevelSpin.Value()
+		apply()
+	})
+
+	criticalLevelRow := handy.NewActionRow()
+	criticalLevelRow.Add(criticalLevelSpin)
+	criticalLevelRow.SetActivatableWidget(criticalLevelSpin)
+	criticalLevelRow.SetTitle("Critical Level")
+	criticalLevelRow.SetSubtitle("The ratio of the height that segments start using the critical color at.")
+	criticalLevelRow.Show()
+
+	warningColorRow := newColorRow(&ac.SegmentWarningColor, true, apply)
+	warningColorRow.SetTitle("Warning Color")
+	warningColorRow.SetSubtitle("The color of the segments above the warning level.")
+	warningColorRow.Show()
+
+	criticalColorRow := newColorRow(&ac.SegmentCriticalColor, true, apply)
+	criticalColorRow.SetTitle("Critical Color")
+	criticalColorRow.SetSubtitle("The color of the segments above the critical level.")
+	criticalColorRow.Show()
+
+	segmentGroup := handy.NewPreferencesGroup()
+	segmentGroup.SetTitle("Segments")
+	segmentGroup.Add(segmentHeightRow)
+	segmentGroup.Add(segmentGapRow)
+	segmentGroup.Add(warningLevelRow)
+	segmentGroup.Add(criticalLevelRow)
+	segmentGroup.Add(warningColorRow)
+	segmentGroup.Add(criticalColorRow)
+	segmentGroup.Show()
+
+	innerRadiusSpin := gtk.NewSpinButtonWithRange(0, 0.95, 0.05)
+	innerRadiusSpin.SetVAlign(gtk.AlignCenter)
+	innerRadiusSpin.SetDigits(2)
+	innerRadiusSpin.SetValue(ac.RadialInnerRadius)
+	innerRadiusSpin.Show()
+	innerRadiusSpin.Connect("value-changed", func(innerRadiusSpin *gtk.SpinButton) {
+		ac.RadialInnerRadius = innerRadiusSpin.Value()
+		apply()
+	})
+
+	innerRadiusRow := handy.NewActionRow()
+	innerRadiusRow.Add(innerRadiusSpin)
+	innerRadiusRow.SetActivatableWidget(innerRadiusSpin)
+	innerRadiusRow.SetTitle("Inner Radius")
+	innerRadiusRow.SetSubtitle("The radius that the bars start from as a ratio of the outer radius.")
+	innerRadiusRow.Show()
+
+	startAngleSpin := gtk.NewSpinButtonWithRange(-360, 360, 15)
+	startAngleSpin.SetVAlign(gtk.AlignCenter)
+	startAngleSpin.SetDigits(0)
+	startAngleSpin.SetValue(ac.RadialStartAngle)
+	startAngleSpin.Show()
+	startAngleSpin.Connect("value-changed", func(startAngleSpin *gtk.SpinButton) {
+		ac.RadialStartAngle = startAngleSpin.Value()
+		apply()
+	})
+
+	startAngleRow := handy.NewActionRow()
+	startAngleRow.Add(startAngleSpin)
+	startAngleRow.SetActivatableWidget(startAngleSpin)
+	startAngleRow.SetTitle("Start Angle (°)")
+	startAngleRow.SetSubtitle("The angle clockwise from the top to start drawing from.")
+	startAngleRow.Show()
+
+	sweepSpin := gtk.NewSpinButtonWithRange(15, 360, 15)
+	sweepSpin.SetVAlign(gtk.AlignCenter)
+	sweepSpin.SetDigits(0)
+	sweepSpin.SetValue(ac.RadialSweep)
+	sweepSpin.Show()
+	sweepSpin.Connect("value-changed", func(sweepSpin *gtk.SpinButton) {
+		ac.RadialSweep = sweepSpin.Value()
+		apply()
+	})
+
+	sweepRow := handy.NewActionRow()
+	sweepRow.Add(sweepSpin)
+	sweepRow.SetActivatableWidget(sweepSpin)
+	sweepRow.SetTitle("Sweep (°)")
+	sweepRow.SetSubtitle("How much of the circle to draw over; 360 is a full circle.")
+	sweepRow.Show()
+
+	radialLines := gtk.NewSwitch()
+	radialLines.SetVAlign(gtk.AlignCenter)
+	radialLines.SetActive(ac.RadialLines)
+	radialLines.Show()
+	radialLines.Connect("state-set", func(radialLines *gtk.Switch, state bool) {
+		ac.RadialLines = state
+		apply()
+	})
+
+	radialLinesRow := handy.NewActionRow()
+	radialLinesRow.Add(radialLines)
+	radialLinesRow.SetActivatableWidget(radialLines)
+	radialLinesRow.SetTitle("Draw Lines")
+	radialLinesRow.SetSubtitle("If enabled, will draw a smoothed line instead of bars.")
+	radialLinesRow.Show()
+
+	radialGroup := handy.NewPreferencesGroup()
+	radialGroup.SetTitle("Radial")
+	radialGroup.Add(innerRadiusRow)
+	radialGroup.Add(startAngleRow)
+	radialGroup.Add(sweepRow)
+	radialGroup.Add(radialLinesRow)
+	radialGroup.Show()
+
+	gainSpin := gtk.NewSpinButtonWithRange(0.1, 100, 0.1)
+	gainSpin.SetVAlign(gtk.AlignCenter)
+	gainSpin.SetDigits(1)
+	gainSpin.SetValue(ac.OscilloscopeGain)
+	gainSpin.Show()
+	gainSpin.Connect("value-changed", func(gainSpin *gtk.SpinButton) {
+		ac.OscilloscopeGain = gainSpin.Value()
+		apply()
+	})
+
+	gainRow := handy.NewActionRow()
+	gainRow.Add(gainSpin)
+	gainRow.SetActivatableWidget(gainSpin)
+	gainRow.SetTitle("Gain")
+	gainRow.SetSubtitle("How much to amplify the waveform by.")
+	gainRow.Show()
+
+	scopeGroup := handy.NewPreferencesGroup()
+	scopeGroup.SetTitle("Oscilloscope")
+	scopeGroup.Add(gainRow)
+	scopeGroup.Show()
+
+	historySpin := gtk.NewSpinButtonWithRange(10, 10000, 10)
+	historySpin.SetVAlign(gtk.AlignCenter)
+	historySpin.SetDigits(0)
+	historySpin.SetValue(float64(ac.SpectrogramHistory))
+	historySpin.Show()
+	historySpin.Connect("value-changed", func(historySpin *gtk.SpinButton) {
+		ac.SpectrogramHistory = historySpin.ValueAsInt()
+		apply()
+	})
+
+	historyRow := handy.NewActionRow()
+	historyRow.Add(historySpin)
+	historyRow.SetActivatableWidget(historySpin)
+	historyRow.SetTitle("History")
+	historyRow.SetSubtitle("The number of frames to keep; one frame is drawn every redraw.")
+	historyRow.Show()
+
+	colorMapCombo := gtk.NewComboBoxText()
+	colorMapCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(colorMapCombo, ColorMapViridis, ColorMapMagma, ColorMapGrayscale, ColorMapCustom)
+	colorMapCombo.SetActiveID(string(ac.SpectrogramColorMap))
+	colorMapCombo.Show()
+	colorMapCombo.Connect("changed", func(colorMapCombo *gtk.ComboBoxText) {
+		ac.SpectrogramColorMap = ColorMap(colorMapCombo.ActiveID())
+		apply()
+	})
+
+	colorMapRow := handy.NewActionRow()
+	colorMapRow.Add(colorMapCombo)
+	colorMapRow.SetActivatableWidget(colorMapCombo)
+	colorMapRow.SetTitle("Color Map")
+	colorMapRow.SetSubtitle("The colors to map the intensity to.")
+	colorMapRow.Show()
+
+	lowColorRow := newColorRow(&ac.SpectrogramLowColor, false, apply)
+	lowColorRow.SetTitle("Custom Low Color")
+	lowColorRow.SetSubtitle("The color of silence for the custom color map.")
+	lowColorRow.Show()
+
+	highColorRow := newColorRow(&ac.SpectrogramHighColor, true, apply)
+	highColorRow.SetTitle("Custom High Color")
+	highColorRow.SetSubtitle("The color of the loudest bars for the custom color map.")
+	highColorRow.Show()
+
+	spectrogramGroup := handy.NewPreferencesGroup()
+	spectrogramGroup.SetTitle("Spectrogram")
+	spectrogramGroup.Add(historyRow)
+	spectrogramGroup.Add(colorMapRow)
+	spectrogramGroup.Add(lowColorRow)
+	spectrogramGroup.Add(highColorRow)
+	spectrogramGroup.Show()
+
+	vectorGainSpin := gtk.NewSpinButtonWithRange(0.1, 100, 0.1)
+	vectorGainSpin.SetVAlign(gtk.AlignCenter)
+	vectorGainSpin.SetDigits(1)
+	vectorGainSpin.SetValue(ac.VectorscopeGain)
+	vectorGainSpin.Show()
+	vectorGainSpin.Connect("value-changed", func(vectorGainSpin *gtk.SpinButton) {
+		ac.VectorscopeGain = vectorGainSpin.Value()
+		apply()
+	})
+
+	vectorGainRow := handy.NewActionRow()
+	vectorGainRow.Add(vectorGainSpin)
+	vectorGainRow.SetActivatableWidget(vectorGainSpin)
+	vectorGainRow.SetTitle("Gain")
+	vectorGainRow.SetSubtitle("How much to amplify the plot by.")
+	vectorGainRow.Show()
+
+	midSide := gtk.NewSwitch()
+	midSide.SetVAlign(gtk.AlignCenter)
+	midSide.SetActive(ac.VectorscopeMidSide)
+	midSide.Show()
+	midSide.Connect("state-set", func(midSide *gtk.Switch, state bool) {
+		ac.VectorscopeMidSide = state
+		apply()
+	})
+
+	midSideRow := handy.NewActionRow()
+	midSideRow.Add(midSide)
+	midSideRow.SetActivatableWidget(midSide)
+	midSideRow.SetTitle("Mid/Side")
+	midSideRow.SetSubtitle("If enabled, will rotate the plot by 45° so mono signals are vertical.")
+	midSideRow.Show()
+
+	persistenceSpin := gtk.NewSpinButtonWithRange(0, 0.99, 0.05)
+	persistenceSpin.SetVAlign(gtk.AlignCenter)
+	persistenceSpin.SetDigits(2)
+	persistenceSpin.SetValue(ac.VectorscopePersistence)
+	persistenceSpin.Show()
+	persistenceSpin.Connect("value-changed", func(persistenceSpin *gtk.SpinButton) {
+		ac.VectorscopePersistence = persistenceSpin.Value()
+		apply()
+	})
+
+	persistenceRow := handy.NewActionRow()
+	persistenceRow.Add(persistenceSpin)
+	persistenceRow.SetActivatableWidget(persistenceSpin)
+	persistenceRow.SetTitle("Persistence")
+	persistenceRow.SetSubtitle("How much of the previous frame to keep if Trail Persistence is 0; higher fades slower.")
+	persistenceRow.Show()
+
+	vectorGroup := handy.NewPreferencesGroup()
+	vectorGroup.SetTitle("Vectorscope")
+	vectorGroup.Add(vectorGainRow)
+	vectorGroup.Add(midSideRow)
+	vectorGroup.Add(persistenceRow)
+	vectorGroup.Show()
+
 	fgRow := newColorRow(&ac.ForegroundColor, true, apply)
 	fgRow.SetTitle("Foreground Color")
 	fgRow.SetSubtitle("The color of the visualizer bars.")
@@ -181,12 +635,213 @@ func (ac *Appearance) Page(apply func()) *handy.PreferencesPage {
 	bgRow.SetSubtitle("The color of the background window.")
 	bgRow.Show()
 
+	colorModeCombo := gtk.NewComboBoxText()
+	colorModeCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(colorModeCombo, ColorStatic, ColorAmplitude, ColorLoudness)
+	colorModeCombo.SetActiveID(string(ac.ColorMode))
+	colorModeCombo.Show()
+	colorModeCombo.Connect("changed", func(colorModeCombo *gtk.ComboBoxText) {
+		ac.ColorMode = ColorMode(colorModeCombo.ActiveID())
+		apply()
+	})
+
+	colorModeRow := handy.NewActionRow()
+	colorModeRow.Add(colorModeCombo)
+	colorModeRow.SetActivatableWidget(colorModeCombo)
+	colorModeRow.SetTitle("Color Mode")
+	colorModeRow.SetSubtitle("Whether to color the bars by their own level or the overall loudness.")
+	colorModeRow.Show()
+
+	quietRow := newColorRow(&ac.QuietColor, true, apply)
+	quietRow.SetTitle("Quiet Color")
+	quietRow.SetSubtitle("The color of silence for the amplitude color modes.")
+	quietRow.Show()
+
+	loudRow := newColorRow(&ac.LoudColor, true, apply)
+	loudRow.SetTitle("Loud Color")
+	loudRow.SetSubtitle("The color of the loudest bars for the amplitude color modes.")
+	loudRow.Show()
+
 	colorGroup := handy.NewPreferencesGroup()
 	colorGroup.SetTitle("Colors")
 	colorGroup.Add(fgRow)
 	colorGroup.Add(bgRow)
+	colorGroup.Add(colorModeRow)
+	colorGroup.Add(quietRow)
+	colorGroup.Add(loudRow)
 	colorGroup.Show()
 
+	gradientGroup := newGradientGroup(&ac.Gradient, apply)
+
+	gridFreqs := gtk.NewSwitch()
+	gridFreqs.SetVAlign(gtk.AlignCenter)
+	gridFreqs.SetActive(ac.GridFrequencies)
+	gridFreqs.Show()
+	gridFreqs.Connect("state-set", func(gridFreqs *gtk.Switch, state bool) {
+		ac.GridFrequencies = state
+		apply()
+	})
+
+	gridFreqsRow := handy.NewActionRow()
+	gridFreqsRow.Add(gridFreqs)
+	gridFreqsRow.SetActivatableWidget(gridFreqs)
+	gridFreqsRow.SetTitle("Frequency Lines")
+	gridFreqsRow.SetSubtitle("If enabled, will draw labeled lines at common frequencies.")
+	gridFreqsRow.Show()
+
+	gridDecibelSpin := gtk.NewSpinButtonWithRange(0, 60, 1)
+	gridDecibelSpin.SetVAlign(gtk.AlignCenter)
+	gridDecibelSpin.SetDigits(0)
+	gridDecibelSpin.SetValue(ac.GridDecibelStep)
+	gridDecibelSpin.Show()
+	gridDecibelSpin.Connect("value-changed", func(gridDecibelSpin *gtk.SpinButton) {
+		ac.GridDecibelStep = gridDecibelSpin.Value()
+		apply()
+	})
+
+	gridDecibelRow := handy.NewActionRow()
+	gridDecibelRow.Add(gridDecibelSpin)
+	gridDecibelRow.SetActivatableWidget(gridDecibelSpin)
+	gridDecibelRow.SetTitle("Amplitude Line Step (dB)")
+	gridDecibelRow.SetSubtitle("The distance between the labeled amplitude lines; 0 to disable.")
+	gridDecibelRow.Show()
+
+	gridColorRow := newColorRow(&ac.GridColor, true, apply)
+	gridColorRow.SetTitle("Grid Color")
+	gridColorRow.SetSubtitle("The color of the grid lines and labels.")
+	gridColorRow.Show()
+
+	gridGroup := handy.NewPreferencesGroup()
+	gridGroup.SetTitle("Grid")
+	gridGroup.Add(gridFreqsRow)
+	gridGroup.Add(gridDecibelRow)
+	gridGroup.Add(gridColorRow)
+	gridGroup.Show()
+
+	glowRadiusSpin := gtk.NewSpinButtonWithRange(0, 100, 1)
+	glowRadiusSpin.SetVAlign(gtk.AlignCenter)
+	glowRadiusSpin.SetDigits(0)
+	glowRadiusSpin.SetValue(ac.GlowRadius)
+	glowRadiusSpin.Show()
+	glowRadiusSpin.Connect("value-changed", func(glowRadiusSpin *gtk.SpinButton) {
+		ac.GlowRadius = glowRadiusSpin.Value()
+		apply()
+	})
+
+	glowRadiusRow := handy.NewActionRow()
+	glowRadiusRow.Add(glowRadiusSpin)
+	glowRadiusRow.SetActivatableWidget(glowRadiusSpin)
+	glowRadiusRow.SetTitle("Glow Radius")
+	glowRadiusRow.SetSubtitle("The blur radius of the glow; 0 to disable.")
+	glowRadiusRow.Show()
+
+	glowIntensitySpin := gtk.NewSpinButtonWithRange(0, 5, 0.1)
+	glowIntensitySpin.SetVAlign(gtk.AlignCenter)
+	glowIntensitySpin.SetDigits(1)
+	glowIntensitySpin.SetValue(ac.GlowIntensity)
+	glowIntensitySpin.Show()
+	glowIntensitySpin.Connect("value-changed", func(glowIntensitySpin *gtk.SpinButton) {
+		ac.GlowIntensity = glowIntensitySpin.Value()
+		apply()
+	})
+
+	glowIntensityRow := handy.NewActionRow()
+	glowIntensityRow.Add(glowIntensitySpin)
+	glowIntensityRow.SetActivatableWidget(glowIntensitySpin)
+	glowIntensityRow.SetTitle("Glow Intensity")
+	glowIntensityRow.SetSubtitle("How strong the glow is; above 1 draws it more than once.")
+	glowIntensityRow.Show()
+
+	glowColorRow := newColorRow(&ac.GlowColor, true, apply)
+	glowColorRow.SetTitle("Glow Color")
+	glowColorRow.SetSubtitle("The color of the glow.")
+	glowColorRow.Show()
+
+	shadowXSpin := gtk.NewSpinButtonWithRange(-50, 50, 1)
+	shadowXSpin.SetVAlign(gtk.AlignCenter)
+	shadowXSpin.SetDigits(0)
+	shadowXSpin.SetValue(ac.ShadowOffsetX)
+	shadowXSpin.Show()
+	shadowXSpin.Connect("value-changed", func(shadowXSpin *gtk.SpinButton) {
+		ac.ShadowOffsetX = shadowXSpin.Value()
+		apply()
+	})
+
+	shadowXRow := handy.NewActionRow()
+	shadowXRow.Add(shadowXSpin)
+	shadowXRow.SetActivatableWidget(shadowXSpin)
+	shadowXRow.SetTitle("Shadow Offset X")
+	shadowXRow.SetSubtitle("The horizontal offset of the drop shadow.")
+	shadowXRow.Show()
+
+	shadowYSpin := gtk.NewSpinButtonWithRange(-50, 50, 1)
+	shadowYSpin.SetVAlign(gtk.AlignCenter)
+	shadowYSpin.SetDigits(0)
+	shadowYSpin.SetValue(ac.ShadowOffsetY)
+	shadowYSpin.Show()
+	shadowYSpin.Connect("value-changed", func(shadowYSpin *gtk.SpinButton) {
+		ac.ShadowOffsetY = shadowYSpin.Value()
+		apply()
+	})
+
+	shadowYRow := handy.NewActionRow()
+	shadowYRow.Add(shadowYSpin)
+	shadowYRow.SetActivatableWidget(shadowYSpin)
+	shadowYRow.SetTitle("Shadow Offset Y")
+	shadowYRow.SetSubtitle("The vertical offset of the drop shadow.")
+	shadowYRow.Show()
+
+	shadowBlurSpin := gtk.NewSpinButtonWithRange(0, 100, 1)
+	shadowBlurSpin.SetVAlign(gtk.AlignCenter)
+	shadowBlurSpin.SetDigits(0)
+	shadowBlurSpin.SetValue(ac.ShadowBlur)
+	shadowBlurSpin.Show()
+	shadowBlurSpin.Connect("value-changed", func(shadowBlurSpin *gtk.SpinButton) {
+		ac.ShadowBlur = shadowBlurSpin.Value()
+		apply()
+	})
+
+	shadowBlurRow := handy.NewActionRow()
+	shadowBlurRow.Add(shadowBlurSpin)
+	shadowBlurRow.SetActivatableWidget(shadowBlurSpin)
+	shadowBlurRow.SetTitle("Shadow Blur")
+	shadowBlurRow.SetSubtitle("The blur radius of the drop shadow; 0 for a sharp shadow.")
+	shadowBlurRow.Show()
+
+	shadowColorRow := newColorRow(&ac.ShadowColor, false, apply)
+	shadowColorRow.SetTitle("Shadow Color")
+	shadowColorRow.SetSubtitle("The color of the drop shadow.")
+	shadowColorRow.Show()
+
+	trailSpin := gtk.NewSpinButtonWithRange(0, 0.99, 0.05)
+	trailSpin.SetVAlign(gtk.AlignCenter)
+	trailSpin.SetDigits(2)
+	trailSpin.SetValue(ac.Persistence)
+	trailSpin.Show()
+	trailSpin.Connect("value-changed", func(trailSpin *gtk.SpinButton) {
+		ac.Persistence = trailSpin.Value()
+		apply()
+	})
+
+	trailRow := handy.NewActionRow()
+	trailRow.Add(trailSpin)
+	trailRow.SetActivatableWidget(trailSpin)
+	trailRow.SetTitle("Trail Persistence")
+	trailRow.SetSubtitle("How much of the previous frame to keep as a trail; 0 to disable.")
+	trailRow.Show()
+
+	effectsGroup := handy.NewPreferencesGroup()
+	effectsGroup.SetTitle("Effects")
+	effectsGroup.Add(glowRadiusRow)
+	effectsGroup.Add(glowIntensityRow)
+	effectsGroup.Add(glowColorRow)
+	effectsGroup.Add(shadowXRow)
+	effectsGroup.Add(shadowYRow)
+	effectsGroup.Add(shadowBlurRow)
+	effectsGroup.Add(shadowColorRow)
+	effectsGroup.Add(trailRow)
+	effectsGroup.Show()
+
 	cssText := gtk.NewTextView()
 	cssText.SetBorderWidth(5)
 	cssText.SetMonospace(true)
@@ -217,12 +872,29 @@ func (ac *Appearance) Page(apply func()) *handy.PreferencesPage {
 	page.SetTitle("Appearance")
 	page.SetIconName("applications-graphics-symbolic")
 	page.Add(barGroup)
+	page.Add(peakGroup)
+	page.Add(segmentGroup)
+	page.Add(radialGroup)
+	page.Add(scopeGroup)
+	page.Add(spectrogramGroup)
+	page.Add(vectorGroup)
 	page.Add(colorGroup)
+	page.Add(gradientGroup)
+	page.Add(gridGroup)
+	page.Add(effectsGroup)
 	page.Add(cssGroup)
 
 	return page
 }
 
+// RendererName returns the name of the renderer to draw with.
+func (ac *Appearance) RendererName() string {
+	if ac.Renderer != "" {
+		return ac.Renderer
+	}
+	return ac.DrawStyle.String()
+}
+
 func addCombo(c *gtk.ComboBoxText, vs ...interface{}) {
 	for _, v := range vs {
 		s := fmt.Sprint(v)
@@ -303,6 +975,75 @@ func (lc LineCap) AsLineCap() cairo.LineCap {
 	}
 }
 
+type Orientation string
+
+const (
+	OrientBottomUp    Orientation = "Bottom Up"
+	OrientTopDown     Orientation = "Top Down"
+	OrientLeftToRight Orientation = "Left to Right"
+	OrientRightToLeft Orientation = "Right to Left"
+)
+
+func (o Orientation) AsOrientation() catnip.Orientation {
+	switch o {
+	case OrientBottomUp:
+		return catnip.OrientBottomUp
+	case OrientTopDown:
+		return catnip.OrientTopDown
+	case OrientLeftToRight:
+		return catnip.OrientLeftToRight
+	case OrientRightToLeft:
+		return catnip.OrientRightToLeft
+	default:
+		return catnip.OrientBottomUp
+	}
+}
+
+type ColorMode string
+
+const (
+	ColorStatic    ColorMode = "Static"
+	ColorAmplitude ColorMode = "Amplitude"
+	ColorLoudness  ColorMode = "Loudness"
+)
+
+func (cm ColorMode) AsColorMode() catnip.ColorMode {
+	switch cm {
+	case ColorStatic:
+		return catnip.ColorStatic
+	case ColorAmplitude:
+		return catnip.ColorAmplitude
+	case ColorLoudness:
+		return catnip.ColorLoudness
+	default:
+		return catnip.ColorStatic
+	}
+}
+
+type ColorMap string
+
+const (
+	ColorMapViridis   ColorMap = "Viridis"
+	ColorMapMagma     ColorMap = "Magma"
+	ColorMapGrayscale ColorMap = "Grayscale"
+	ColorMapCustom    ColorMap = "Custom"
+)
+
+func (cm ColorMap) AsColorMap() catnip.ColorMap {
+	switch cm {
+	case ColorMapViridis:
+		return catnip.ColorMapViridis
+	case ColorMapMagma:
+		return catnip.ColorMapMagma
+	case ColorMapGrayscale:
+		return catnip.ColorMapGrayscale
+	case ColorMapCustom:
+		return catnip.ColorMapCustom
+	default:
+		return catnip.ColorMapViridis
+	}
+}
+
 type AntiAlias string
 
 const (
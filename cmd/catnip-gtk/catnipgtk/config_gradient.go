@@ -0,0 +1,182 @@
+package catnipgtk
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/catnip-gtk"
+	"github.com/diamondburned/gotk4-handy/pkg/handy"
+	"github.com/diamondburned/gotk4/pkg/gdk/v3"
+	"github.com/diamondburned/gotk4/pkg/gtk/v3"
+)
+
+type Gradient struct {
+	Kind  GradientKind
+	Stops []GradientStop
+}
+
+type GradientStop struct {
+	Offset float64
+	Color  catnip.CairoColor
+}
+
+// AsGradient converts the gradient to a catnip gradient. It returns nil if the
+// gradient is disabled.
+func (g Gradient) AsGradient() *catnip.Gradient {
+	kind, ok := g.Kind.AsGradientKind()
+	if !ok || len(g.Stops) == 0 {
+		return nil
+	}
+
+	stops := make([]catnip.GradientStop, len(g.Stops))
+	for i, stop := range g.Stops {
+		stops[i] = catnip.GradientStop{
+			Offset: stop.Offset,
+			Color:  stop.Color,
+		}
+	}
+
+	return &catnip.Gradient{
+		Kind:  kind,
+		Stops: stops,
+	}
+}
+
+type GradientKind string
+
+const (
+	GradientNone      GradientKind = "None"
+	GradientFrequency GradientKind = "Frequency"
+	GradientAmplitude GradientKind = "Amplitude"
+	GradientRadial    GradientKind = "Radial"
+)
+
+func (gk GradientKind) AsGradientKind() (catnip.GradientKind, bool) {
+	switch gk {
+	case GradientFrequency:
+		return catnip.GradientFrequency, true
+	case GradientAmplitude:
+		return catnip.GradientAmplitude, true
+	case GradientRadial:
+		return catnip.GradientRadial, true
+	default:
+		return 0, false
+	}
+}
+
+func newGradientGroup(g *Gradient, apply func()) *handy.PreferencesGroup {
+	kindCombo := gtk.NewComboBoxText()
+	kindCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(kindCombo, GradientNone, GradientFrequency, GradientAmplitude, GradientRadial)
+	kindCombo.SetActiveID(string(g.Kind))
+	kindCombo.Show()
+	kindCombo.Connect("changed", func(kindCombo *gtk.ComboBoxText) {
+		g.Kind = GradientKind(kindCombo.ActiveID())
+		apply()
+	})
+
+	kindRow := handy.NewActionRow()
+	kindRow.Add(kindCombo)
+	kindRow.SetActivatableWidget(kindCombo)
+	kindRow.SetTitle("Gradient")
+	kindRow.SetSubtitle("The direction of the gradient; it replaces the foreground color.")
+	kindRow.Show()
+
+	group := handy.NewPreferencesGroup()
+	group.SetTitle("Gradient")
+	group.Add(kindRow)
+	group.Show()
+
+	var rows []*handy.ActionRow
+	var rebuild func()
+
+	rebuild = func() {
+		for _, row := range rows {
+			row.Destroy()
+		}
+		rows = rows[:0]
+
+		for i := range g.Stops {
+			row := newGradientStopRow(g, i, apply, rebuild)
+			row.Show()
+			group.Add(row)
+			rows = append(rows, row)
+		}
+
+		add := gtk.NewButtonFromIconName("list-add-symbolic", int(gtk.IconSizeButton))
+		add.SetRelief(gtk.ReliefNone)
+		add.SetVAlign(gtk.AlignCenter)
+		add.Show()
+		add.Connect("clicked", func(add *gtk.Button) {
+			stop := GradientStop{Offset: 1}
+			if n := len(g.Stops); n > 0 {
+				stop.Color = g.Stops[n-1].Color
+			} else {
+				stop.Color = catnip.CairoColor{1, 1, 1, 1}
+			}
+
+			g.Stops = append(g.Stops, stop)
+			rebuild()
+			apply()
+		})
+
+		addRow := handy.NewActionRow()
+		addRow.Add(add)
+		addRow.SetActivatableWidget(add)
+		addRow.SetTitle("Add Stop")
+		addRow.SetSubtitle("Add a color stop to the end of the gradient.")
+		addRow.Show()
+
+		group.Add(addRow)
+		rows = append(rows, addRow)
+	}
+
+	rebuild()
+
+	return group
+}
+
+func newGradientStopRow(g *Gradient, i int, apply, rebuild func()) *handy.ActionRow {
+	stop := &g.Stops[i]
+
+	offsetSpin := gtk.NewSpinButtonWithRange(0, 1, 0.05)
+	offsetSpin.SetVAlign(gtk.AlignCenter)
+	offsetSpin.SetDigits(2)
+	offsetSpin.SetValue(stop.Offset)
+	offsetSpin.Show()
+	offsetSpin.Connect("value-changed", func(offsetSpin *gtk.SpinButton) {
+		stop.Offset = offsetSpin.Value()
+		apply()
+	})
+
+	rgba := gdk.NewRGBA(stop.Color[0], stop.Color[1], stop.Color[2], stop.Color[3])
+
+	colorButton := gtk.NewColorButton()
+	colorButton.SetVAlign(gtk.AlignCenter)
+	colorButton.SetUseAlpha(true)
+	colorButton.SetRGBA(&rgba)
+	colorButton.Show()
+	colorButton.Connect("color-set", func(interface{}) { // hack around lack of marshaler
+		stop.Color = catnip.ColorFromGDK(colorButton.RGBA())
+		apply()
+	})
+
+	remove := gtk.NewButtonFromIconName("list-remove-symbolic", int(gtk.IconSizeButton))
+	remove.SetRelief(gtk.ReliefNone)
+	remove.SetVAlign(gtk.AlignCenter)
+	remove.SetTooltipText("Remove")
+	remove.Show()
+	remove.Connect("clicked", func(remove *gtk.Button) {
+		g.Stops = append(g.Stops[:i], g.Stops[i+1:]...)
+		rebuild()
+		apply()
+	})
+
+	row := handy.NewActionRow()
+	row.AddPrefix(remove)
+	row.Add(offsetSpin)
+	row.Add(colorButton)
+	row.SetTitle(fmt.Sprintf("Stop %d", i+1))
+	row.SetSubtitle("The offset and the color of this stop.")
+
+	return row
+}
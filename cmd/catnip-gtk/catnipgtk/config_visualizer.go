@@ -2,6 +2,7 @@ package catnipgtk
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/diamondburned/catnip-gtk"
 	"github.com/diamondburned/gotk4-handy/pkg/handy"
@@ -15,10 +16,28 @@ type Visualizer struct {
 	SampleRate float64
 	SampleSize int
 	FrameRate  int
+	// Overlap is the percentage of each block that overlaps the last one.
+	Overlap float64
+	// FFTSize is the size that each block is zero-padded to; 0 to not pad.
+	FFTSize int
+
+	FrameClock   bool
+	MaxFrameRate int
+	Interpolate  bool
+	BufferDepth  int
 
 	WindowFn     WindowFn
 	SmoothFactor float64
 
+	MinFrequency   float64
+	MaxFrequency   float64
+	FrequencyScale FrequencyScale
+	Analyzer       Analyzer
+	// BassCrossover and TrebleCrossover split the bands of the multi-resolution
+	// analyzer; 0 to not split there.
+	BassCrossover   float64
+	TrebleCrossover float64
+
 	ScaleSlowWindow     float64
 	ScaleFastWindow     float64
 	ScaleDumpPercent    float64
@@ -31,9 +50,19 @@ func NewVisualizer() Visualizer {
 		SampleSize: 1024,
 		FrameRate:  60,
 
+		BufferDepth: 4,
+
 		SmoothFactor: 65.69,
 		WindowFn:     BlackmanHarris,
 
+		MinFrequency:   0,
+		MaxFrequency:   0,
+		FrequencyScale: ScaleLogarithmic,
+		Analyzer:       AnalyzerFFT,
+
+		BassCrossover:   250,
+		TrebleCrossover: 2500,
+
 		ScaleSlowWindow:     5,
 		ScaleFastWindow:     4,
 		ScaleDumpPercent:    0.75,
@@ -41,11 +70,21 @@ func NewVisualizer() Visualizer {
 	}
 }
 
+// HopSize returns the number of samples between the starts of the overlapping
+// sample blocks.
+func (v Visualizer) HopSize() int {
+	hop := int(math.Round(float64(v.SampleSize) * (1 - v.Overlap/100)))
+	if hop < 1 {
+		hop = 1
+	}
+	return hop
+}
+
 func (v *Visualizer) Page(apply func()) *handy.PreferencesPage {
 	samplingGroup := handy.NewPreferencesGroup()
 
 	updateSamplingLabel := func() {
-		fₛ := v.SampleRate / float64(v.SampleSize)
+		fₛ := v.SampleRate / float64(v.HopSize())
 		samplingGroup.SetTitle(fmt.Sprintf(
 			"Sampling and Drawing (fₛ ≈ %.1f samples/s, latency ≈ %.1fms)",
 			fₛ, 1000/fₛ,
@@ -89,6 +128,43 @@ func (v *Visualizer) Page(apply func()) *handy.PreferencesPage {
 	sampleSizeRow.SetSubtitle("The sample size to record; higher is more accurate but slower.")
 	sampleSizeRow.Show()
 
+	fftSizeSpin := gtk.NewSpinButtonWithRange(0, 409600, 1024)
+	fftSizeSpin.SetVAlign(gtk.AlignCenter)
+	fftSizeSpin.SetDigits(0)
+	fftSizeSpin.SetValue(float64(v.FFTSize))
+	fftSizeSpin.Show()
+	fftSizeSpin.Connect("value-changed", func(fftSizeSpin *gtk.SpinButton) {
+		v.FFTSize = fftSizeSpin.ValueAsInt()
+		apply()
+	})
+
+	fftSizeRow := handy.NewActionRow()
+	fftSizeRow.Add(fftSizeSpin)
+	fftSizeRow.SetActivatableWidget(fftSizeSpin)
+	fftSizeRow.SetTitle("FFT Size")
+	fftSizeRow.SetSubtitle("The size to zero-pad each sample block to for smoother bars; " +
+		"0 or less than the sample size to not pad.")
+	fftSizeRow.Show()
+
+	overlapSpin := gtk.NewSpinButtonWithRange(0, 95, 5)
+	overlapSpin.SetVAlign(gtk.AlignCenter)
+	overlapSpin.SetDigits(1)
+	overlapSpin.SetValue(v.Overlap)
+	overlapSpin.Show()
+	overlapSpin.Connect("value-changed", func(overlapSpin *gtk.SpinButton) {
+		v.Overlap = overlapSpin.Value()
+		updateSamplingLabel()
+		apply()
+	})
+
+	overlapRow := handy.NewActionRow()
+	overlapRow.Add(overlapSpin)
+	overlapRow.SetActivatableWidget(overlapSpin)
+	overlapRow.SetTitle("Overlap (%)")
+	overlapRow.SetSubtitle("How much each sample block overlaps the last one; " +
+		"higher updates more often at the same sample size.")
+	overlapRow.Show()
+
 	frameRateSpin := gtk.NewSpinButtonWithRange(5, 240, 5)
 	frameRateSpin.SetVAlign(gtk.AlignCenter)
 	frameRateSpin.SetDigits(0)
@@ -107,9 +183,84 @@ func (v *Visualizer) Page(apply func()) *handy.PreferencesPage {
 		"this affects the smoothing.")
 	frameRateRow.Show()
 
+	maxFrameRateSpin := gtk.NewSpinButtonWithRange(0, 360, 5)
+	maxFrameRateSpin.SetVAlign(gtk.AlignCenter)
+	maxFrameRateSpin.SetDigits(0)
+	maxFrameRateSpin.SetValue(float64(v.MaxFrameRate))
+	maxFrameRateSpin.SetSensitive(v.FrameClock)
+	maxFrameRateSpin.Show()
+	maxFrameRateSpin.Connect("value-changed", func(maxFrameRateSpin *gtk.SpinButton) {
+		v.MaxFrameRate = maxFrameRateSpin.ValueAsInt()
+		apply()
+	})
+
+	maxFrameRateRow := handy.NewActionRow()
+	maxFrameRateRow.Add(maxFrameRateSpin)
+	maxFrameRateRow.SetActivatableWidget(maxFrameRateSpin)
+	maxFrameRateRow.SetTitle("Maximum Frame Rate (fps)")
+	maxFrameRateRow.SetSubtitle("The frame rate cap when synced to the monitor; 0 for no cap.")
+	maxFrameRateRow.Show()
+
+	frameClock := gtk.NewSwitch()
+	frameClock.SetVAlign(gtk.AlignCenter)
+	frameClock.SetActive(v.FrameClock)
+	frameClock.Show()
+	frameClock.Connect("state-set", func(frameClock *gtk.Switch, state bool) {
+		v.FrameClock = state
+		maxFrameRateSpin.SetSensitive(state)
+		apply()
+	})
+
+	frameClockRow := handy.NewActionRow()
+	frameClockRow.Add(frameClock)
+	frameClockRow.SetActivatableWidget(frameClock)
+	frameClockRow.SetTitle("Sync to Monitor")
+	frameClockRow.SetSubtitle("If enabled, will draw on every frame of the monitor instead of at the frame rate.")
+	frameClockRow.Show()
+
+	interpolate := gtk.NewSwitch()
+	interpolate.SetVAlign(gtk.AlignCenter)
+	interpolate.SetActive(v.Interpolate)
+	interpolate.Show()
+	interpolate.Connect("state-set", func(interpolate *gtk.Switch, state bool) {
+		v.Interpolate = state
+		apply()
+	})
+
+	interpolateRow := handy.NewActionRow()
+	interpolateRow.Add(interpolate)
+	interpolateRow.SetActivatableWidget(interpolate)
+	interpolateRow.SetTitle("Interpolate Frames")
+	interpolateRow.SetSubtitle("If enabled, will smoothly animate between samples; " +
+		"useful for large sample sizes at high frame rates.")
+	interpolateRow.Show()
+
+	bufferDepthSpin := gtk.NewSpinButtonWithRange(1, 32, 1)
+	bufferDepthSpin.SetVAlign(gtk.AlignCenter)
+	bufferDepthSpin.SetDigits(0)
+	bufferDepthSpin.SetValue(float64(v.BufferDepth))
+	bufferDepthSpin.Show()
+	bufferDepthSpin.Connect("value-changed", func(bufferDepthSpin *gtk.SpinButton) {
+		v.BufferDepth = bufferDepthSpin.ValueAsInt()
+		apply()
+	})
+
+	bufferDepthRow := handy.NewActionRow()
+	bufferDepthRow.Add(bufferDepthSpin)
+	bufferDepthRow.SetActivatableWidget(bufferDepthSpin)
+	bufferDepthRow.SetTitle("Buffer Depth (blocks)")
+	bufferDepthRow.SetSubtitle("The number of sample blocks to queue for processing before dropping them.")
+	bufferDepthRow.Show()
+
 	samplingGroup.Add(sampleRateRow)
 	samplingGroup.Add(sampleSizeRow)
+	samplingGroup.Add(overlapRow)
+	samplingGroup.Add(fftSizeRow)
 	samplingGroup.Add(frameRateRow)
+	samplingGroup.Add(frameClockRow)
+	samplingGroup.Add(maxFrameRateRow)
+	samplingGroup.Add(interpolateRow)
+	samplingGroup.Add(bufferDepthRow)
 	samplingGroup.Show()
 
 	windowCombo := gtk.NewComboBoxText()
@@ -154,15 +305,182 @@ func (v *Visualizer) Page(apply func()) *handy.PreferencesPage {
 	signalProcGroup.Add(smoothFactorRow)
 	signalProcGroup.Show()
 
+	minFreqSpin := gtk.NewSpinButtonWithRange(0, 96000, 10)
+	minFreqSpin.SetVAlign(gtk.AlignCenter)
+	minFreqSpin.SetDigits(0)
+	minFreqSpin.SetValue(v.MinFrequency)
+	minFreqSpin.Show()
+	minFreqSpin.Connect("value-changed", func(minFreqSpin *gtk.SpinButton) {
+		v.MinFrequency = minFreqSpin.Value()
+		apply()
+	})
+
+	minFreqRow := handy.NewActionRow()
+	minFreqRow.Add(minFreqSpin)
+	minFreqRow.SetActivatableWidget(minFreqSpin)
+	minFreqRow.SetTitle("Minimum Frequency (Hz)")
+	minFreqRow.SetSubtitle("The frequency of the first bar; 0 for the lowest possible. Leaving both at 0 with the logarithmic scale keeps the original 60 Hz to 8 kHz bars.")
+	minFreqRow.Show()
+
+	maxFreqSpin := gtk.NewSpinButtonWithRange(0, 96000, 100)
+	maxFreqSpin.SetVAlign(gtk.AlignCenter)
+	maxFreqSpin.SetDigits(0)
+	maxFreqSpin.SetValue(v.MaxFrequency)
+	maxFreqSpin.Show()
+	maxFreqSpin.Connect("value-changed", func(maxFreqSpin *gtk.SpinButton) {
+		v.MaxFrequency = maxFreqSpin.Value()
+		apply()
+	})
+
+	maxFreqRow := handy.NewActionRow()
+	maxFreqRow.Add(maxFreqSpin)
+	maxFreqRow.SetActivatableWidget(maxFreqSpin)
+	maxFreqRow.SetTitle("Maximum Frequency (Hz)")
+	maxFreqRow.SetSubtitle("The frequency of the last bar; 0 for the highest possible.")
+	maxFreqRow.Show()
+
+	freqScaleCombo := gtk.NewComboBoxText()
+	freqScaleCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(freqScaleCombo, ScaleLogarithmic, ScaleLinear, ScaleMel, ScaleBark)
+	freqScaleCombo.SetActiveID(string(v.FrequencyScale))
+	freqScaleCombo.Show()
+	freqScaleCombo.Connect("changed", func(freqScaleCombo *gtk.ComboBoxText) {
+		v.FrequencyScale = FrequencyScale(freqScaleCombo.ActiveID())
+		apply()
+	})
+
+	freqScaleRow := handy.NewActionRow()
+	freqScaleRow.Add(freqScaleCombo)
+	freqScaleRow.SetActivatableWidget(freqScaleCombo)
+	freqScaleRow.SetTitle("Frequency Scale")
+	freqScaleRow.SetSubtitle("The distribution of the bars along the frequency axis.")
+	freqScaleRow.Show()
+
+	bassCrossoverSpin := gtk.NewSpinButtonWithRange(0, 96000, 10)
+	bassCrossoverSpin.SetVAlign(gtk.AlignCenter)
+	bassCrossoverSpin.SetDigits(0)
+	bassCrossoverSpin.SetValue(v.BassCrossover)
+	bassCrossoverSpin.Show()
+	bassCrossoverSpin.Connect("value-changed", func(bassCrossoverSpin *gtk.SpinButton) {
+		v.BassCrossover = bassCrossoverSpin.Value()
+		apply()
+	})
+
+	bassCrossoverRow := handy.NewActionRow()
+	bassCrossoverRow.Add(bassCrossoverSpin)
+	bassCrossoverRow.SetActivatableWidget(bassCrossoverSpin)
+	bassCrossoverRow.SetTitle("Bass Crossover (Hz)")
+	bassCrossoverRow.SetSubtitle("The frequency below which the whole sample size is analyzed; " +
+		"0 to not split there.")
+	bassCrossoverRow.Show()
+
+	trebleCrossoverSpin := gtk.NewSpinButtonWithRange(0, 96000, 100)
+	trebleCrossoverSpin.SetVAlign(gtk.AlignCenter)
+	trebleCrossoverSpin.SetDigits(0)
+	trebleCrossoverSpin.SetValue(v.TrebleCrossover)
+	trebleCrossoverSpin.Show()
+	trebleCrossoverSpin.Connect("value-changed", func(trebleCrossoverSpin *gtk.SpinButton) {
+		v.TrebleCrossover = trebleCrossoverSpin.Value()
+		apply()
+	})
+
+	trebleCrossoverRow := handy.NewActionRow()
+	trebleCrossoverRow.Add(trebleCrossoverSpin)
+	trebleCrossoverRow.SetActivatableWidget(trebleCrossoverSpin)
+	trebleCrossoverRow.SetTitle("Treble Crossover (Hz)")
+	trebleCrossoverRow.SetSubtitle("The frequency above which the shortest blocks are analyzed; " +
+		"0 to not split there.")
+	trebleCrossoverRow.Show()
+
+	analyzerCombo := gtk.NewComboBoxText()
+	analyzerCombo.SetVAlign(gtk.AlignCenter)
+	addCombo(analyzerCombo, AnalyzerFFT, AnalyzerMultiResolution, AnalyzerConstantQ)
+	analyzerCombo.SetActiveID(string(v.Analyzer))
+	analyzerCombo.Show()
+	analyzerCombo.Connect("changed", func(analyzerCombo *gtk.ComboBoxText) {
+		v.Analyzer = Analyzer(analyzerCombo.ActiveID())
+		freqScaleCombo.SetSensitive(v.Analyzer != AnalyzerConstantQ)
+		bassCrossoverSpin.SetSensitive(v.Analyzer == AnalyzerMultiResolution)
+		trebleCrossoverSpin.SetSensitive(v.Analyzer == AnalyzerMultiResolution)
+		apply()
+	})
+
+	freqScaleCombo.SetSensitive(v.Analyzer != AnalyzerConstantQ)
+	bassCrossoverSpin.SetSensitive(v.Analyzer == AnalyzerMultiResolution)
+	trebleCrossoverSpin.SetSensitive(v.Analyzer == AnalyzerMultiResolution)
+
+	analyzerRow := handy.NewActionRow()
+	analyzerRow.Add(analyzerCombo)
+	analyzerRow.SetActivatableWidget(analyzerCombo)
+	analyzerRow.SetTitle("Analyzer")
+	analyzerRow.SetSubtitle("The transform to analyze the samples with; " +
+		"Multi-Resolution analyzes the treble over shorter blocks to react quicker, " +
+		"and Constant-Q always spaces the bars musically.")
+	analyzerRow.Show()
+
+	frequencyGroup := handy.NewPreferencesGroup()
+	frequencyGroup.SetTitle("Frequencies")
+	frequencyGroup.Add(minFreqRow)
+	frequencyGroup.Add(maxFreqRow)
+	frequencyGroup.Add(freqScaleRow)
+	frequencyGroup.Add(analyzerRow)
+	frequencyGroup.Add(bassCrossoverRow)
+	frequencyGroup.Add(trebleCrossoverRow)
+	frequencyGroup.Show()
+
 	page := handy.NewPreferencesPage()
 	page.SetTitle("Visualizer")
 	page.SetIconName("preferences-desktop-display-symbolic")
 	page.Add(samplingGroup)
 	page.Add(signalProcGroup)
+	page.Add(frequencyGroup)
 
 	return page
 }
 
+type FrequencyScale string
+
+const (
+	ScaleLogarithmic FrequencyScale = "Logarithmic"
+	ScaleLinear      FrequencyScale = "Linear"
+	ScaleMel         FrequencyScale = "Mel"
+	ScaleBark        FrequencyScale = "Bark"
+)
+
+func (fs FrequencyScale) AsFrequencyScale() catnip.FrequencyScale {
+	switch fs {
+	case ScaleLogarithmic:
+		return catnip.ScaleLogarithmic
+	case ScaleLinear:
+		return catnip.ScaleLinear
+	case ScaleMel:
+		return catnip.ScaleMel
+	case ScaleBark:
+		return catnip.ScaleBark
+	default:
+		return catnip.ScaleLogarithmic
+	}
+}
+
+type Analyzer string
+
+const (
+	AnalyzerFFT             Analyzer = "FFT"
+	AnalyzerMultiResolution Analyzer = "Multi-Resolution FFT"
+	AnalyzerConstantQ       Analyzer = "Constant-Q"
+)
+
+func (a Analyzer) AsNewAnalyzer() func(catnip.Config) catnip.Analyzer {
+	switch a {
+	case AnalyzerMultiResolution:
+		return catnip.NewMultiResolutionAnalyzer
+	case AnalyzerConstantQ:
+		return catnip.NewConstantQAnalyzer
+	default:
+		return catnip.NewFFTAnalyzer
+	}
+}
+
 type WindowFn string
 
 const (
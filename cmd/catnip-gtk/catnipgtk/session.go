@@ -88,6 +88,7 @@ func (s *Session) reload() {
 
 	s.Stack.SetVisibleChild(s.Area)
 	s.Drawer = drawer
+	s.reportOverruns(drawer)
 
 	go func() {
 		if err := drawer.Start(); err != nil {
@@ -103,6 +104,29 @@ func (s *Session) reload() {
 	}()
 }
 
+// reportOverruns logs the sample blocks that the drawer drops every second
+// while it's the session's drawer.
+func (s *Session) reportOverruns(drawer *catnip.Drawer) {
+	var last catnip.InputStats
+
+	glib.TimeoutSecondsAdd(1, func() bool {
+		if s.Drawer != drawer {
+			return false
+		}
+
+		stats := drawer.InputStats()
+		if stats.DroppedBlocks > last.DroppedBlocks {
+			log.Printf(
+				"Dropped %d sample blocks in %d overruns; the visualizer can't keep up",
+				stats.DroppedBlocks-last.DroppedBlocks, stats.Overruns-last.Overruns,
+			)
+			last = stats
+		}
+
+		return true
+	})
+}
+
 func errorText(err error) string {
 	return fmt.Sprintf(
 		`<span color="red"><b>Error:</b> %s</span>`,
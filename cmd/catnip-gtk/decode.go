@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/noriah/catnip/input"
+	"github.com/pkg/errors"
+)
+
+// decodeFile decodes an audio file with ffmpeg and calls fn with every block
+// of SampleSize samples of each channel in order. The last block is shorter if
+// the file doesn't end on a whole block. Unlike the ffmpeg input session,
+// which keeps up with the wall clock by dropping samples, every sample is
+// passed to fn exactly once, so the files can be decoded as fast as possible.
+func decodeFile(path string, cfg input.SessionConfig, fn func([][]input.Sample) error) error {
+	cmd := exec.Command(
+		"ffmpeg", "-hide_banner", "-loglevel", "error",
+		"-i", path,
+		"-ar", fmt.Sprintf("%.0f", cfg.SampleRate),
+		"-ac", strconv.Itoa(cfg.FrameSize),
+		"-f", "f64le",
+		"-",
+	)
+	cmd.Stderr = os.Stderr
+
+	o, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "failed to get stdout pipe")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start ffmpeg")
+	}
+
+	if err := readBlocks(bufio.NewReader(o), cfg, fn); err != nil {
+		// Don't bother decoding the rest of the file.
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return errors.Wrap(err, "failed to decode audio")
+	}
+
+	return nil
+}
+
+// readBlocks reads interleaved little-endian float64 samples into blocks.
+func readBlocks(r io.Reader, cfg input.SessionConfig, fn func([][]input.Sample) error) error {
+	const sampleBytes = 8
+
+	block := input.MakeBuffers(cfg)
+	buf := make([]byte, sampleBytes*cfg.FrameSize*cfg.SampleSize)
+
+	for {
+		n, err := io.ReadFull(r, buf)
+
+		if samples := n / (sampleBytes * cfg.FrameSize); samples > 0 {
+			for i := 0; i < samples*cfg.FrameSize; i++ {
+				bits := binary.LittleEndian.Uint64(buf[i*sampleBytes:])
+				block[i%cfg.FrameSize][i/cfg.FrameSize] = math.Float64frombits(bits)
+			}
+
+			out := block
+			if samples < cfg.SampleSize {
+				out = make([][]input.Sample, len(block))
+				for ch := range block {
+					out[ch] = block[ch][:samples]
+				}
+			}
+
+			if err := fn(out); err != nil {
+				return err
+			}
+		}
+
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return nil
+		default:
+			return errors.Wrap(err, "failed to read samples")
+		}
+	}
+}
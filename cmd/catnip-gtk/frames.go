@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strings"
+	"unsafe"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/pkg/errors"
+)
+
+// frameWriter writes rendered frames.
+type frameWriter interface {
+	WriteFrame(n int, surface *cairo.Surface) error
+	Close() error
+}
+
+// newFrameWriter creates a frame writer of the given format. The output is a
+// printf pattern of the file names for png and a file for everything else,
+// where empty or "-" is stdout.
+func newFrameWriter(format, output string, width, height, fps int) (frameWriter, error) {
+	switch format {
+	case "png":
+		if output == "" {
+			output = "%06d.png"
+		}
+		if !strings.Contains(output, "%") {
+			return nil, fmt.Errorf("png output %q is not a printf pattern", output)
+		}
+		return pngWriter(output), nil
+	}
+
+	var w io.WriteCloser = os.Stdout
+	if output != "" && output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create output")
+		}
+		w = f
+	}
+
+	stream := &streamWriter{
+		img:  image.NewNRGBA(image.Rect(0, 0, width, height)),
+		file: w,
+		buf:  bufio.NewWriter(w),
+	}
+
+	switch format {
+	case "y4m":
+		stream.writeFrame = writeY4MFrame
+		// Only write the header once there's a frame, so it's not written if
+		// the input fails.
+		stream.header = fmt.Sprintf("YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C444\n", width, height, fps)
+	case "rgba":
+		stream.writeFrame = writeRGBAFrame
+	default:
+		stream.Close()
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	return stream, nil
+}
+
+// pngWriter writes each frame into its own PNG file.
+type pngWriter string
+
+func (w pngWriter) WriteFrame(n int, surface *cairo.Surface) error {
+	return surface.WriteToPNG(fmt.Sprintf(string(w), n))
+}
+
+func (w pngWriter) Close() error { return nil }
+
+// nativeEndian is the byte order of the pixels of image surfaces, which are
+// native-endian 32-bit integers.
+var nativeEndian = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// surfaceImage copies the pixels of the ARGB32 image surface into img, which
+// must have the same dimensions, as non-premultiplied RGBA.
+func surfaceImage(img *image.NRGBA, surface *cairo.Surface) {
+	surface.Flush()
+
+	data := surface.Data()
+	stride := surface.Stride()
+
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+
+	for y := 0; y < height; y++ {
+		src := data[y*stride : y*stride+width*4]
+		dst := img.Pix[y*img.Stride : y*img.Stride+width*4]
+
+		for x := 0; x < width; x++ {
+			px := nativeEndian.Uint32(src[x*4:])
+			a := px >> 24
+
+			dst[x*4+0] = unpremultiply(px>>16&0xFF, a)
+			dst[x*4+1] = unpremultiply(px>>8&0xFF, a)
+			dst[x*4+2] = unpremultiply(px&0xFF, a)
+			dst[x*4+3] = uint8(a)
+		}
+	}
+}
+
+// unpremultiply divides the premultiplied color channel by the alpha.
+func unpremultiply(c, a uint32) uint8 {
+	if a == 0 {
+		return 0
+	}
+	return uint8((c*0xFF + a/2) / a)
+}
+
+// streamWriter writes all frames into one stream.
+type streamWriter struct {
+	// img is the frame being written, which is reused for every frame.
+	img    *image.NRGBA
+	file   io.WriteCloser
+	buf    *bufio.Writer
+	header string
+
+	writeFrame func(w *bufio.Writer, img *image.NRGBA) error
+}
+
+func (w *streamWriter) WriteFrame(n int, surface *cairo.Surface) error {
+	surfaceImage(w.img, surface)
+
+	if w.header != "" {
+		if _, err := w.buf.WriteString(w.header); err != nil {
+			return err
+		}
+		w.header = ""
+	}
+
+	return w.writeFrame(w.buf, w.img)
+}
+
+func (w *streamWriter) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+
+	return w.file.Close()
+}
+
+// writeRGBAFrame writes the raw pixels, which can be read by ffmpeg with
+// -f rawvideo -pix_fmt rgba.
+func writeRGBAFrame(w *bufio.Writer, img *image.NRGBA) error {
+	rowLen := img.Rect.Dx() * 4
+
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+rowLen]
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeY4MFrame writes the frame as planar BT.601 YCbCr without chroma
+// subsampling. Y4M has no alpha, so the frame is drawn over black.
+func writeY4MFrame(w *bufio.Writer, img *image.NRGBA) error {
+	if _, err := w.WriteString("FRAME\n"); err != nil {
+		return err
+	}
+
+	width := img.Rect.Dx()
+	height := img.Rect.Dy()
+
+	planes := make([]byte, width*height*3)
+	yPlane := planes[:width*height]
+	uPlane := planes[width*height : width*height*2]
+	vPlane := planes[width*height*2:]
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			px := img.Pix[y*img.Stride+x*4 : y*img.Stride+x*4+4]
+			a := float64(px[3]) / 0xFF
+			r := float64(px[0]) / 0xFF * a
+			g := float64(px[1]) / 0xFF * a
+			b := float64(px[2]) / 0xFF * a
+
+			i := y*width + x
+			yPlane[i] = uint8(16 + 65.481*r + 128.553*g + 24.966*b + 0.5)
+			uPlane[i] = uint8(128 - 37.797*r - 74.203*g + 112.0*b + 0.5)
+			vPlane[i] = uint8(128 + 112.0*r - 93.786*g - 18.214*b + 0.5)
+		}
+	}
+
+	_, err := w.Write(planes)
+	return err
+}
@@ -16,6 +16,21 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "render":
+			if err := renderCommand(os.Args[2:]); err != nil {
+				log.Fatalln("failed to render:", err)
+			}
+			return
+		case "spectrogram":
+			if err := spectrogramCommand(os.Args[2:]); err != nil {
+				log.Fatalln("failed to export spectrogram:", err)
+			}
+			return
+		}
+	}
+
 	cfg, err := catnipgtk.ReadUserConfig()
 	if err != nil {
 		log.Fatalln("failed to read config:", err)
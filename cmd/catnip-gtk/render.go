@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"os"
+
+	"github.com/diamondburned/catnip-gtk"
+	"github.com/diamondburned/catnip-gtk/cmd/catnip-gtk/catnipgtk"
+	"github.com/noriah/catnip/input"
+	"github.com/pkg/errors"
+)
+
+// renderCommand renders an audio file into a stream of video frames using the
+// user's config.
+func renderCommand(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: catnip-gtk render [flags] <audio file>")
+		fmt.Fprintln(flags.Output(), "The custom CSS of the config is not applied, since there is no window to style.")
+		flags.PrintDefaults()
+	}
+
+	var (
+		configPath = flags.String("config", catnipgtk.UserConfigPath, "the config file to use")
+		format     = flags.String("format", "png", "the output format: png, y4m or rgba")
+		output     = flags.String("o", "", "the output file, or the printf pattern of the file names for png; "+
+			"defaults to stdout or %06d.png")
+		width  = flags.Int("width", 0, "the width of the frames; defaults to the window width")
+		height = flags.Int("height", 0, "the height of the frames; defaults to the window height")
+	)
+
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := catnipgtk.ReadConfig(*configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read config")
+	}
+
+	if *width == 0 {
+		*width = cfg.WindowSize.Width
+	}
+	if *height == 0 {
+		*height = cfg.WindowSize.Height
+	}
+
+	catnipCfg := cfg.Transform()
+	fps := catnipCfg.FrameRate
+
+	// There is no GTK theme to take the foreground from, and the default black
+	// would be invisible over the black that y4m composites onto.
+	if catnipCfg.Colors.Foreground == nil {
+		catnipCfg.Colors.Foreground = color.White
+	}
+
+	frames, err := newFrameWriter(*format, *output, *width, *height, fps)
+	if err != nil {
+		return err
+	}
+
+	r := newFrameRenderer(catnipCfg, *width, *height, frames)
+
+	err = decodeFile(flags.Arg(0), r.sessionConfig, r.process)
+	if err == nil {
+		err = r.flush()
+	}
+	if err != nil {
+		frames.Close()
+		return err
+	}
+
+	if err := frames.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish writing frames")
+	}
+
+	log.Printf("rendered %d frames at %d fps", r.frame, fps)
+	return nil
+}
+
+// frameRenderer renders the frames of the decoded samples. The samples are fed
+// one hop at a time like the input of a running drawer, and each frame is
+// rendered once its samples are decoded, so the output only depends on the
+// audio and never on the wall clock.
+type frameRenderer struct {
+	drawer *catnip.Drawer
+	frames frameWriter
+
+	// sessionConfig is what the samples are decoded as. Its SampleSize is one
+	// hop.
+	sessionConfig input.SessionConfig
+	// decoded is the number of samples decoded so far.
+	decoded int
+
+	width  int
+	height int
+	fps    int
+	frame  int
+}
+
+func newFrameRenderer(cfg catnip.Config, width, height int, frames frameWriter) *frameRenderer {
+	channels := 2
+	if cfg.Monophonic {
+		channels = 1
+	}
+
+	hop := cfg.HopSize
+	if hop <= 0 || hop > cfg.SampleSize {
+		hop = cfg.SampleSize
+	}
+
+	r := &frameRenderer{
+		drawer: catnip.NewHeadless(cfg),
+		frames: frames,
+		sessionConfig: input.SessionConfig{
+			FrameSize:  channels,
+			SampleSize: hop,
+			SampleRate: cfg.SampleRate,
+		},
+		width:  width,
+		height: height,
+		fps:    cfg.FrameRate,
+	}
+
+	// Lay out the bars for the frame size before feeding anything.
+	r.drawer.Render(width, height)
+
+	return r
+}
+
+// process renders every frame that ends within the given decoded hop.
+func (r *frameRenderer) process(block [][]input.Sample) error {
+	if err := r.drawer.Feed(block); err != nil {
+		return errors.Wrap(err, "failed to process samples")
+	}
+
+	r.decoded += len(block[0])
+
+	for r.frameEnd(r.frame) <= r.decoded {
+		if err := r.render(1 / float64(r.fps)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flush renders the samples decoded after the last whole frame as one final,
+// shorter frame.
+func (r *frameRenderer) flush() error {
+	rest := r.decoded - r.frameEnd(r.frame-1)
+	if rest <= 0 {
+		return nil
+	}
+
+	return r.render(float64(rest) / r.sessionConfig.SampleRate)
+}
+
+// render advances the drawer by dt seconds and writes the next frame.
+func (r *frameRenderer) render(dt float64) error {
+	r.drawer.Advance(dt)
+
+	surface := r.drawer.Render(r.width, r.height)
+
+	if err := r.frames.WriteFrame(r.frame, surface); err != nil {
+		return errors.Wrapf(err, "failed to write frame %d", r.frame)
+	}
+
+	r.frame++
+	return nil
+}
+
+// frameEnd returns the number of samples up to the end of the given frame.
+// It is rounded from the exact time of the frame instead of adding up rounded
+// frame lengths, so that the frames never drift away from the frame rate.
+func (r *frameRenderer) frameEnd(frame int) int {
+	return int(math.Round(float64(frame+1) * r.sessionConfig.SampleRate / float64(r.fps)))
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diamondburned/catnip-gtk"
+	"github.com/diamondburned/catnip-gtk/cmd/catnip-gtk/catnipgtk"
+	"github.com/noriah/catnip/input"
+	"github.com/pkg/errors"
+)
+
+// spectrogramMargin is the width of the label column in SVG spectrograms.
+const spectrogramMargin = 48
+
+// spectrogramCommand analyzes a whole audio file and exports its spectrogram
+// as a PNG or SVG image using the user's config.
+func spectrogramCommand(args []string) error {
+	flags := flag.NewFlagSet("spectrogram", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: catnip-gtk spectrogram [flags] -o <image> <audio file>")
+		flags.PrintDefaults()
+	}
+
+	var (
+		configPath = flags.String("config", catnipgtk.UserConfigPath, "the config file to use")
+		output     = flags.String("o", "", "the output image, which is an SVG if it ends with .svg and a PNG otherwise")
+		hop        = flags.Int("hop", 0, "the number of samples between columns; defaults to half the sample size")
+		height     = flags.Int("height", 512, "the number of frequency bars, which is the height of the image")
+		dbRange    = flags.Float64("range", 90, "the dynamic range in dB")
+	)
+
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *output == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := catnipgtk.ReadConfig(*configPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read config")
+	}
+
+	catnipCfg := cfg.Transform()
+
+	if *hop <= 0 {
+		*hop = catnipCfg.SampleSize / 2
+	}
+
+	a := newSpectrogramAnalyzer(catnipCfg, *height, *hop)
+
+	if err := decodeFile(flags.Arg(0), a.sessionConfig, a.process); err != nil {
+		return err
+	}
+
+	if a.spectrogram.Len() == 0 {
+		return errors.New("no audio was decoded")
+	}
+
+	img := a.spectrogram.Image(*dbRange)
+
+	if strings.EqualFold(filepath.Ext(*output), ".svg") {
+		err = writeSpectrogramSVG(*output, a.spectrogram, img)
+	} else {
+		err = writeSpectrogramPNG(*output, img)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write image")
+	}
+
+	log.Printf("exported %d columns of %d bars", a.spectrogram.Len(), a.spectrogram.Bars())
+	return nil
+}
+
+// spectrogramAnalyzer adds a spectrogram column for every decoded hop of
+// samples.
+type spectrogramAnalyzer struct {
+	spectrogram *catnip.Spectrogram
+
+	// sessionConfig is what the samples are decoded as. Its SampleSize is one
+	// hop.
+	sessionConfig input.SessionConfig
+}
+
+func newSpectrogramAnalyzer(cfg catnip.Config, bars, hop int) *spectrogramAnalyzer {
+	channels := 2
+	if cfg.Monophonic {
+		channels = 1
+	}
+
+	a := &spectrogramAnalyzer{
+		spectrogram: catnip.NewSpectrogram(cfg, bars),
+		sessionConfig: input.SessionConfig{
+			FrameSize:  channels,
+			SampleSize: hop,
+			SampleRate: cfg.SampleRate,
+		},
+	}
+
+	return a
+}
+
+// process adds the column of the given decoded hop.
+func (a *spectrogramAnalyzer) process(block [][]input.Sample) error {
+	a.spectrogram.Add(block)
+	return nil
+}
+
+func writeSpectrogramPNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeSpectrogramSVG writes the spectrogram as an embedded PNG with labeled
+// frequency lines next to it.
+func writeSpectrogramSVG(path string, s *catnip.Spectrogram, img image.Image) error {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		return err
+	}
+
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		width+spectrogramMargin, height)
+	fmt.Fprintf(&svg,
+		`<image x="%d" y="0" width="%d" height="%d" preserveAspectRatio="none" href="data:image/png;base64,%s"/>`+"\n",
+		spectrogramMargin, width, height, base64.StdEncoding.EncodeToString(pngBuf.Bytes()))
+
+	fmt.Fprintln(&svg, `<g font-family="sans-serif" font-size="11" text-anchor="end" dominant-baseline="middle">`)
+
+	for _, hz := range catnip.GridFrequencies {
+		pos := s.BarPosition(hz)
+		if math.IsNaN(pos) || pos < -0.5 || pos > float64(s.Bars())-0.5 {
+			continue
+		}
+
+		// The lowest bar is at the bottom.
+		y := float64(height) - pos - 0.5
+
+		fmt.Fprintf(&svg,
+			`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="gray" stroke-opacity="0.5"/>`+"\n",
+			spectrogramMargin-4, y, spectrogramMargin+width, y)
+		fmt.Fprintf(&svg,
+			`<text x="%d" y="%.1f">%s</text>`+"\n",
+			spectrogramMargin-6, y, catnip.FormatFrequency(hz))
+	}
+
+	fmt.Fprintln(&svg, "</g>")
+	fmt.Fprintln(&svg, "</svg>")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := svg.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,131 @@
+package catnip
+
+import (
+	"math"
+
+	"github.com/noriah/catnip/input"
+)
+
+// constantQMinFrequency is the lowest frequency of the constant-Q analyzer if
+// MinFrequency is not set, which is the lowest A of a piano.
+const constantQMinFrequency = 27.5
+
+// constantQAnalyzer is a constant-Q transform. Its bins are spaced evenly in
+// pitch and each one is as many cycles long, so the low bins look further back
+// in time than the high ones.
+type constantQAnalyzer struct {
+	sampleRate float64
+	sampleSize int
+	lo, hi     float64
+
+	kernels []constantQKernel
+}
+
+// constantQKernel is the windowed complex sinusoid of a bin. It is applied to
+// the last len(cos) samples.
+type constantQKernel struct {
+	cos []float64
+	sin []float64
+	// gain is the blockGain of the kernel, since the higher the bin, the
+	// shorter it is.
+	gain float64
+}
+
+// NewConstantQAnalyzer creates an Analyzer that gives musically spaced bars,
+// which all cover the same fraction of an octave. The bars are laid out from
+// MinFrequency to MaxFrequency, and each bin is at most SampleSize samples
+// long, which limits how narrow the lowest bars can be. Each bin has its own
+// Hann window, so WindowFn, FFTSize and FrequencyScale are not used.
+func NewConstantQAnalyzer(cfg Config) Analyzer {
+	lo := cfg.MinFrequency
+	if lo <= 0 {
+		lo = constantQMinFrequency
+	}
+
+	nyquist := cfg.SampleRate / 2
+
+	hi := nyquist
+	if cfg.MaxFrequency > 0 {
+		hi = math.Min(cfg.MaxFrequency, nyquist)
+	}
+	if hi <= lo {
+		lo, hi = constantQMinFrequency, nyquist
+	}
+
+	return &constantQAnalyzer{
+		sampleRate: cfg.SampleRate,
+		sampleSize: cfg.SampleSize,
+		lo:         lo,
+		hi:         hi,
+	}
+}
+
+func (a *constantQAnalyzer) Recalculate(bars int) int {
+	switch {
+	case bars > a.sampleSize/2:
+		bars = a.sampleSize / 2
+	case bars < 1:
+		bars = 1
+	}
+
+	// Each bar spans the same ratio of frequencies, and Q is the ratio of
+	// its center frequency to its width.
+	ratio := math.Pow(a.hi/a.lo, 1/float64(bars))
+	q := 1 / (ratio - 1)
+
+	a.kernels = make([]constantQKernel, bars)
+
+	for bar := range a.kernels {
+		hz := a.lo * math.Pow(ratio, float64(bar)+0.5)
+
+		length := int(math.Ceil(q * a.sampleRate / hz))
+		if length > a.sampleSize {
+			length = a.sampleSize
+		}
+		if length < 2 {
+			length = 2
+		}
+
+		kernel := constantQKernel{
+			cos:  make([]float64, length),
+			sin:  make([]float64, length),
+			gain: blockGain(a.sampleSize, length),
+		}
+
+		for n := 0; n < length; n++ {
+			// Hann window.
+			w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(n)/float64(length-1))
+			phase := 2 * math.Pi * hz * float64(n) / a.sampleRate
+
+			kernel.cos[n] = w * math.Cos(phase)
+			kernel.sin[n] = w * math.Sin(phase)
+		}
+
+		a.kernels[bar] = kernel
+	}
+
+	return bars
+}
+
+func (a *constantQAnalyzer) Analyze(samples [][]input.Sample, bars [][]float64) {
+	for ch, buf := range bars {
+		samples := samples[ch]
+
+		for bar := range buf {
+			kernel := a.kernels[bar]
+			block := samples[len(samples)-len(kernel.cos):]
+
+			var re, im float64
+			for n, sample := range block {
+				re += sample * kernel.cos[n]
+				im -= sample * kernel.sin[n]
+			}
+
+			buf[bar] = math.Hypot(re, im) * kernel.gain
+		}
+	}
+}
+
+func (a *constantQAnalyzer) BarPosition(hz float64, bars int) float64 {
+	return math.Log(hz/a.lo)/math.Log(a.hi/a.lo)*float64(bars) - 0.5
+}
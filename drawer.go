@@ -5,13 +5,13 @@ import (
 	"image/color"
 	"math"
 	"sync"
+	"sync/atomic"
 
 	"github.com/diamondburned/gotk4/pkg/cairo"
 	"github.com/diamondburned/gotk4/pkg/core/glib"
 	"github.com/diamondburned/gotk4/pkg/gdk/v3"
 	"github.com/diamondburned/gotk4/pkg/gtk/v3"
-	"github.com/noriah/catnip/dsp"
-	"github.com/noriah/catnip/fft"
+	"github.com/diamondburned/gotk4/pkg/pango"
 	"github.com/noriah/catnip/input"
 
 	catniputil "github.com/noriah/catnip/util"
@@ -55,8 +55,24 @@ type Drawer struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	fg CairoColor
-	bg CairoColor
+	fg   CairoColor
+	bg   CairoColor
+	peak CairoColor
+	// zones is the color of each level zone of the segments. A nil zone uses
+	// the foreground.
+	zones [3]*CairoColor
+	// quiet and loud are the colors to interpolate between for the amplitude
+	// color modes, and tint is the interpolated color of the current frame
+	// for ColorLoudness.
+	quiet CairoColor
+	loud  CairoColor
+	tint  CairoColor
+	// grid and font are the color and font of the grid overlay.
+	grid CairoColor
+	font *pango.FontDescription
+	// glow and shadow are the colors of the effects.
+	glow   CairoColor
+	shadow CairoColor
 
 	// total bar + space width
 	binWidth float64
@@ -67,12 +83,36 @@ type Drawer struct {
 	device   input.Device
 	inputCfg input.SessionConfig
 
-	fftPlans []*fft.Plan
-	fftBuf   []complex128
-	spectrum dsp.Spectrum
+	// analysis is the state of the analysis. It is only used by the worker,
+	// or by Feed for headless drawers.
+	analysis analysisState
 
-	slowWindow *catniputil.MovingWindow
-	fastWindow *catniputil.MovingWindow
+	// front is the analyzed frame being drawn. It is only used in the main
+	// thread.
+	front *barFrame
+	// frames is the rest of the triple buffer that the worker publishes the
+	// analyzed frames through.
+	frames struct {
+		sync.Mutex
+		// back is the frame that the worker is writing into. It is not
+		// guarded by the mutex.
+		back *barFrame
+		// middle is the last published frame. ready is true if it's newer
+		// than the front frame.
+		middle *barFrame
+		ready  bool
+	}
+
+	// worker is the pending work of the worker goroutine.
+	worker struct {
+		sync.Mutex
+		// wake asks for a frame, and data tells that there are new samples
+		// in the ring buffer.
+		wake chan struct{}
+		data chan struct{}
+		// dt is the time in seconds to advance by in the next frame.
+		dt float64
+	}
 
 	background struct {
 		surface *cairo.Surface
@@ -80,39 +120,141 @@ type Drawer struct {
 		height  float64
 	}
 
-	shared struct {
-		sync.Mutex
+	// renderer draws the frames. It is only used in the main thread.
+	renderer Renderer
 
-		// Input buffers.
-		readBuf  [][]input.Sample
-		writeBuf [][]input.Sample
+	spectrogram struct {
+		// history is the past frames, pushed for every frame that is swapped
+		// in.
+		history spectrogramHistory
+		// ordered is the history from the oldest to the newest frame, which
+		// is reused for every Frame.
+		ordered [][]float64
+	}
 
-		// Output bars.
-		barBufs [][]input.Sample
+	// clock is the state of DrawOptions.FrameClock. It is only used in the
+	// main thread.
+	clock struct {
+		id      uint  // tick callback; 0 if not ticking
+		running bool  // true while Start is running
+		last    int64 // frame time of the last processed frame in µs
+		next    int64 // frame time that the next frame is due at in µs
+	}
 
+	effects struct {
+		// layer is what the visualizer is drawn onto if there are effects
+		// to draw under it or if it has persistence.
+		layer  trail
+		shadow blur
+		glow   blur
+	}
+
+	// writeBuf is what the input session writes into. It is only used by
+	// the session.
+	writeBuf [][]input.Sample
+	// ring is the buffer of samples between the input session and the
+	// analyzer.
+	ring *sampleRing
+	// paused is 1 if the input is silenced. It is accessed atomically.
+	paused uint32
+
+	shared struct {
+		sync.Mutex
 		cairoWidth float64
-		barWidth   float64
-		barCount   int
-		scale      float64
-		peak       float64
-		quiet      int
+	}
+}
+
+// analysisState is the state of the analysis, which is done by the worker.
+type analysisState struct {
+	// history is the last SampleSize samples, which is what is analyzed.
+	history [][]input.Sample
+	// hop is the hop of new samples consumed from the ring buffer. fresh is
+	// true if a hop has been consumed since the last frame.
+	hop   [][]input.Sample
+	fresh bool
+	// Output bars.
+	barBufs [][]input.Sample
+	// Copy of input before the window function is applied. Only used if
+	// the DrawStyle draws samples.
+	waveBuf [][]input.Sample
+	// Falling peak markers. Nil if disabled.
+	peakCaps [][]peakCap
+
+	analyzer Analyzer
+	// bars is the slice of each channel of bars to analyze into.
+	bars [][]float64
+	// oldValues is the last smoothed value of each bar.
+	oldValues [][]float64
+	// smoothing is the ratio of the old values to keep per frame at
+	// FrameRate.
+	smoothing float64
+
+	slowWindow *catniputil.MovingWindow
+	fastWindow *catniputil.MovingWindow
 
-		paused bool
+	// tween is the state of Config.Interpolate.
+	tween struct {
+		prev [][]float64 // bars when the last block arrived
+		next [][]float64 // bars of the last block
+		// elapsed is the time since the last block arrived, and
+		// period is the estimated time between blocks, both in
+		// seconds.
+		elapsed float64
+		period  float64
 	}
+
+	barWidth float64
+	barCount int
+	scale    float64
+	peak     float64
+	quiet    int
 }
 
 const (
 	quietThreshold = 25
 	peakThreshold  = 0.001
+	// quietAlpha is the alpha multiplier of the default quiet color.
+	quietAlpha = 0.25
 )
 
 // NewDrawer creates a separated drawer state. The given drawQueuer will be
 // called every redrawn frame.
 func NewDrawer(widget gtk.Widgetter, cfg Config) *Drawer {
+	d := newDrawer(cfg)
+	d.parent = gtk.BaseWidget(widget)
+
+	w := gtk.BaseWidget(widget)
+
+	d.handle = []glib.SignalHandle{
+		w.Connect("draw", d.Draw),
+		w.Connect("destroy", d.Stop),
+		w.ConnectMap(d.addTick),
+		w.ConnectUnmap(d.removeTick),
+		w.ConnectStyleUpdated(func() {
+			// Invalidate the background.
+			d.background.surface = nil
+
+			styleCtx := w.StyleContext()
+			transparent := gdk.NewRGBA(0, 0, 0, 0)
+
+			d.fg = getColor(d.cfg.Colors.Foreground, styleCtx.Color(gtk.StateFlagNormal), d.fg)
+			d.bg = getColor(d.cfg.Colors.Background, &transparent, d.bg)
+			d.peak = getColor(d.cfg.Colors.Peak, nil, d.fg)
+			d.quiet, d.loud = d.amplitudeColors()
+			d.grid = d.gridColor(ColorFromGDK(styleCtx.Color(gtk.StateFlagNormal)))
+			d.font = w.PangoContext().FontDescription()
+			d.glow = getColor(d.cfg.Colors.Glow, nil, d.fg)
+		}),
+	}
+
+	return d
+}
+
+// newDrawer creates a drawer state without any widget.
+func newDrawer(cfg Config) *Drawer {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	d := &Drawer{
-		parent: gtk.BaseWidget(widget),
 		cfg:    cfg,
 		ctx:    ctx,
 		cancel: cancel,
@@ -121,35 +263,66 @@ func NewDrawer(widget gtk.Widgetter, cfg Config) *Drawer {
 		bg: getColor(cfg.Colors.Background, nil, CairoColor{0, 0, 0, 0}),
 
 		channels: 2,
-		// Weird Cairo tricks require multiplication and division by 2. Unsure
-		// why.
-		binWidth: cfg.BarWidth + (cfg.SpaceWidth * 2),
+		binWidth: cfg.binWidth(),
+	}
+
+	d.peak = getColor(cfg.Colors.Peak, nil, d.fg)
+	d.quiet, d.loud = d.amplitudeColors()
+	d.grid = d.gridColor(d.fg)
+	d.glow = getColor(cfg.Colors.Glow, nil, d.fg)
+	d.shadow = getColor(cfg.Colors.Shadow, nil, shadowColor)
+	d.zones = [3]*CairoColor{
+		nil, // normal
+		optionalColor(cfg.Colors.Warning),
+		optionalColor(cfg.Colors.Critical),
 	}
 
 	if cfg.Monophonic {
 		d.channels = 1
 	}
 
-	w := gtk.BaseWidget(widget)
-
-	d.handle = []glib.SignalHandle{
-		w.Connect("draw", d.Draw),
-		w.Connect("destroy", d.Stop),
-		w.ConnectStyleUpdated(func() {
-			// Invalidate the background.
-			d.background.surface = nil
+	d.ring = &sampleRing{}
+	d.analysis.analyzer = cfg.newAnalyzer()
 
-			styleCtx := w.StyleContext()
-			transparent := gdk.NewRGBA(0, 0, 0, 0)
+	// The built-in renderers are the same as their DrawStyle, which also
+	// lays out the bars and the overlays for them.
+	if r, ok := cfg.Renderer.(*styleRenderer); ok {
+		d.cfg.DrawStyle = r.style
+		d.cfg.Renderer = nil
+	}
 
-			d.fg = getColor(d.cfg.Colors.Foreground, styleCtx.Color(gtk.StateFlagNormal), d.fg)
-			d.bg = getColor(d.cfg.Colors.Background, &transparent, d.bg)
-		}),
+	d.renderer = d.cfg.Renderer
+	if d.renderer == nil {
+		d.renderer = newStyleRenderer(d.cfg.DrawStyle)
 	}
 
+	// Allocate the frames before anything can draw or analyze them. They
+	// draw nothing until the first frame is analyzed.
+	d.initFrames()
+
 	return d
 }
 
+// drawsSamples returns true if the frames need a copy of the samples.
+func (d *Drawer) drawsSamples() bool {
+	return d.cfg.DrawStyle.drawsSamples() || d.cfg.Renderer != nil
+}
+
+// drawsStyle returns true if the frames are drawn by the built-in renderer of
+// the given style rather than a custom one.
+func (d *Drawer) drawsStyle(style DrawStyle) bool {
+	return d.cfg.Renderer == nil && d.cfg.DrawStyle == style
+}
+
+// persistence returns the ratio of the previous frame to keep, which falls
+// back to the one of the vectorscope if it's drawn.
+func (d *Drawer) persistence() float64 {
+	if d.cfg.Persistence == 0 && d.drawsStyle(DrawVectorscope) {
+		return d.cfg.Vectorscope.Persistence
+	}
+	return d.cfg.Persistence
+}
+
 // getColor gets the color from the given c Color interface. If c is nil, then
 // the color is taken from the given gdk.RGBA instead.
 func getColor(c color.Color, rgba *gdk.RGBA, fallback CairoColor) (cairoC CairoColor) {
@@ -178,11 +351,62 @@ func getColor(c color.Color, rgba *gdk.RGBA, fallback CairoColor) (cairoC CairoC
 	return fallback
 }
 
+// optionalColor converts the given color to a CairoColor pointer, or nil if c
+// is nil.
+func optionalColor(c color.Color) *CairoColor {
+	if c == nil {
+		return nil
+	}
+
+	cc := getColor(c, nil, CairoColor{})
+	return &cc
+}
+
+// amplitudeColors returns the quiet and loud colors for the amplitude color
+// modes. The quiet color defaults to a dim foreground, and the loud color
+// defaults to the foreground.
+func (d *Drawer) amplitudeColors() (quiet, loud CairoColor) {
+	dim := d.fg
+	dim[3] *= quietAlpha
+
+	quiet = getColor(d.cfg.Colors.Quiet, nil, dim)
+	loud = getColor(d.cfg.Colors.Loud, nil, d.fg)
+	return
+}
+
+// gridColor returns the color of the grid overlay, which defaults to a dim
+// version of the given color.
+func (d *Drawer) gridColor(fallback CairoColor) CairoColor {
+	fallback[3] *= gridAlpha
+	return getColor(d.cfg.Colors.Grid, nil, fallback)
+}
+
+// resetSource sets the source back to the color of the frame, which is either
+// the background surface or the tint for ColorLoudness.
+func (d *Drawer) resetSource(cr *cairo.Context) {
+	if d.cfg.ColorMode == ColorLoudness {
+		cr.SetSourceRGBA(d.tint[0], d.tint[1], d.tint[2], d.tint[3])
+		return
+	}
+
+	cr.SetSourceSurface(d.background.surface, 0, 0)
+}
+
 // SetPaused will silent all inputs if true.
 func (d *Drawer) SetPaused(paused bool) {
-	d.shared.Lock()
-	d.shared.paused = paused
-	d.shared.Unlock()
+	var value uint32
+	if paused {
+		value = 1
+	}
+	atomic.StoreUint32(&d.paused, value)
+}
+
+// InputStats returns the statistics of the buffer between the input session
+// and the analyzer so far. It can be called from any goroutine while the
+// drawer is running. The buffer is drained as the samples arrive, so blocks
+// are only dropped if the worker can't keep up.
+func (d *Drawer) InputStats() InputStats {
+	return d.ring.stats()
 }
 
 // AllocatedSizeGetter is any widget that can be obtained dimensions of. This is
@@ -216,16 +440,40 @@ func (d *Drawer) Draw(widget gtk.Widgetter, cr *cairo.Context) {
 	w := gtk.BaseWidget(widget)
 
 	alloc := w.Allocation()
-	width := float64(d.cfg.even(alloc.Width()))
-	height := float64(d.cfg.even(alloc.Height()))
+	d.draw(cr, alloc.Width(), alloc.Height(), w.StyleContext())
+}
+
+// draw draws the frame within the given dimensions. The CSS background is
+// only drawn if style is not nil.
+func (d *Drawer) draw(cr *cairo.Context, w, h int, style *gtk.StyleContext) {
+	width := float64(d.cfg.even(w))
+	height := float64(d.cfg.even(h))
+
+	cr.SetSourceRGBA(d.bg[0], d.bg[1], d.bg[2], d.bg[3])
+	cr.Paint()
+
+	// Keep the untransformed dimensions for the effects and the grid.
+	boxWidth, boxHeight := width, height
+
+	// Draw onto an offscreen layer instead if there are effects to draw under
+	// the visualizer or previous frames to keep.
+	target := cr
+	effects := d.cfg.Glow.enabled() || d.cfg.Shadow.enabled()
+	persistence := d.persistence()
+	layered := effects || persistence > 0
+	if layered {
+		cr = d.effects.layer.begin(target.GetTarget(), int(width), int(height), persistence)
+	}
 
 	cr.SetAntialias(d.cfg.AntiAlias)
 	cr.SetLineWidth(d.cfg.BarWidth)
 	cr.SetLineJoin(d.cfg.LineJoin)
 	cr.SetLineCap(d.cfg.LineCap)
 
-	cr.SetSourceRGBA(d.bg[0], d.bg[1], d.bg[2], d.bg[3])
-	cr.Paint()
+	// Draw everything else within the orientation's coordinate space, which
+	// might have its width and height swapped.
+	cr.Save()
+	width, height = d.cfg.orient(cr, width, height)
 
 	if d.background.surface == nil || d.background.width != width || d.background.height != height {
 		// Render the background onto the surface and use that as the source
@@ -234,89 +482,196 @@ func (d *Drawer) Draw(widget gtk.Widgetter, cr *cairo.Context) {
 
 		cr := cairo.Create(surface)
 
-		// Draw the user-requested line color.
-		cr.SetSourceRGBA(d.fg[0], d.fg[1], d.fg[2], d.fg[3])
+		// Draw the user-requested line color or gradient.
+		if pattern := d.gradientPattern(width, height); pattern != nil {
+			cr.SetSource(pattern)
+		} else {
+			cr.SetSourceRGBA(d.fg[0], d.fg[1], d.fg[2], d.fg[3])
+		}
 		cr.Paint()
 
 		// Draw the CSS background.
-		gtk.RenderBackground(w.StyleContext(), cairo.Create(surface), 0, 0, width, height)
+		if style != nil {
+			gtk.RenderBackground(style, cairo.Create(surface), 0, 0, width, height)
+		}
 
 		d.background.width = width
 		d.background.height = height
 		d.background.surface = surface
 	}
 
-	cr.SetSourceSurface(d.background.surface, 0, 0)
+	if d.cfg.ColorMode == ColorLoudness {
+		d.tint = interpolateColor([]CairoColor{d.quiet, d.loud}, d.front.peak/d.front.scale)
+	}
+
+	d.resetSource(cr)
+
+	var cairoWidth float64
+	switch {
+	case d.cfg.Renderer != nil:
+		cairoWidth = width
+	case d.cfg.DrawStyle == DrawRadial:
+		cairoWidth = d.cfg.Radial.arcLength(width, height)
+	case d.cfg.DrawStyle == DrawSpectrogram:
+		// Spectrograms draw the bars along the height.
+		cairoWidth = height
+	default:
+		cairoWidth = width
+	}
 
 	d.shared.Lock()
-	defer d.shared.Unlock()
+	d.shared.cairoWidth = cairoWidth
+	d.shared.Unlock()
+
+	d.renderer.Render(cr, d.frame(width, height))
+
+	cr.Restore()
 
-	d.shared.cairoWidth = width
+	if layered {
+		cr = target
 
-	switch d.cfg.DrawStyle {
-	case DrawVerticalBars:
-		d.drawVertically(width, height, cr)
-	case DrawHorizontalBars:
-		d.drawHorizontally(width, height, cr)
-	case DrawLines:
-		d.drawLines(width, height, cr)
+		if effects {
+			d.drawEffects(cr, boxWidth, boxHeight)
+		} else {
+			d.effects.layer.paint(cr)
+		}
+	}
+
+	if d.cfg.Grid.enabled() && d.cfg.Renderer == nil {
+		d.drawGrid(boxWidth, boxHeight, cr)
 	}
 }
 
-func (d *Drawer) drawVertically(width, height float64, cr *cairo.Context) {
-	bins := d.shared.barBufs
-	center := (height - d.cfg.MinimumClamp) / 2
-	scale := center / d.shared.scale
+// frame creates the Frame to render from the front frame.
+func (d *Drawer) frame(width, height float64) Frame {
+	f := Frame{
+		Width:         width,
+		Height:        height,
+		Bars:          make([][]float64, len(d.front.bars)),
+		Samples:       d.front.samples,
+		Scale:         d.front.scale,
+		Peak:          d.front.peak,
+		MinimumClamp:  d.cfg.MinimumClamp,
+		Foreground:    d.fg,
+		Background:    d.bg,
+		PeakColor:     d.peak,
+		QuietColor:    d.quiet,
+		LoudColor:     d.loud,
+		WarningColor:  d.zones[1],
+		CriticalColor: d.zones[2],
+		Options:       d.cfg.DrawOptions,
+	}
+
+	for i, buf := range d.front.bars {
+		f.Bars[i] = buf[:d.front.count]
+	}
+
+	if d.front.peakCaps != nil {
+		f.PeakCaps = make([][]float64, len(d.front.peakCaps))
+		for i, buf := range d.front.peakCaps {
+			f.PeakCaps[i] = buf[:d.front.count]
+		}
+	}
+
+	if history := &d.spectrogram.history; history.frames != nil {
+		d.spectrogram.ordered = history.ordered(d.spectrogram.ordered[:0])
+		f.History = d.spectrogram.ordered
+		f.HistoryCount = history.count
+	}
+
+	return f
+}
+
+func drawVertically(cr *cairo.Context, f Frame) {
+	bins := f.Bars
+	count := len(bins[0])
+	binWidth := f.Options.binWidth()
+	center := (f.Height - f.MinimumClamp) / 2
+	scale := center / f.Scale
 
 	if center < 0 {
 		center = 0
 	}
 
 	// Round up the width so we don't draw a partial bar.
-	xColMax := math.Round(width/d.binWidth) * d.binWidth
+	xColMax := math.Round(f.Width/binWidth) * binWidth
 
 	// Calculate the starting position so it's in the middle.
-	xCol := d.binWidth/2 + (width-xColMax)/2
+	xCol := binWidth/2 + (f.Width-xColMax)/2
 
 	lBins := bins[0]
 	rBins := bins[1%len(bins)]
 
-	for xBin := 0; xBin < d.shared.barCount && xCol < xColMax; xBin++ {
-		lStop := calculateBar(lBins[xBin]*scale, center, d.cfg.MinimumClamp)
-		rStop := calculateBar(rBins[xBin]*scale, center, d.cfg.MinimumClamp)
+	for xBin := 0; xBin < count && xCol < xColMax; xBin++ {
+		lStop := calculateBar(lBins[xBin]*scale, center, f.MinimumClamp)
+		rStop := calculateBar(rBins[xBin]*scale, center, f.MinimumClamp)
+
+		colored := f.setBarColor(cr, math.Max(lBins[xBin], rBins[xBin])/f.Scale)
+
+		if !math.IsNaN(lStop) && !math.IsNaN(rStop) && f.Options.Segments.enabled() {
+			// Segments are drawn from the center outwards, so each channel
+			// has to be drawn separately.
+			f.drawSegments(cr, xCol, center, lStop, center)
+			f.drawSegments(cr, xCol, f.Height-center, f.Height-rStop, center)
+		} else if !math.IsNaN(lStop) && !math.IsNaN(rStop) {
+			f.drawBar(cr, xCol, lStop, f.Height-rStop)
+		} else if f.MinimumClamp > 0 {
+			f.drawBar(cr, xCol, center, center+f.MinimumClamp)
+		}
 
-		if !math.IsNaN(lStop) && !math.IsNaN(rStop) {
-			d.drawBar(cr, xCol, lStop, height-rStop)
-		} else if d.cfg.MinimumClamp > 0 {
-			d.drawBar(cr, xCol, center, center+d.cfg.MinimumClamp)
+		if colored {
+			cr.Restore()
 		}
 
-		xCol += d.binWidth
+		if f.PeakCaps != nil {
+			// The left channel grows up from the center, and the right
+			// channel grows down.
+			f.drawPeakCap(cr, f.PeakCaps[0][xBin], xCol, center, center, -1)
+			f.drawPeakCap(cr, f.PeakCaps[1%len(bins)][xBin], xCol, f.Height-center, center, 1)
+		}
+
+		xCol += binWidth
 	}
 }
 
-func (d *Drawer) drawHorizontally(width, height float64, cr *cairo.Context) {
-	bins := d.shared.barBufs
-	scale := height / d.shared.scale
+func drawHorizontally(cr *cairo.Context, f Frame) {
+	bins := f.Bars
+	count := len(bins[0])
+	binWidth := f.Options.binWidth()
+	scale := f.Height / f.Scale
 
 	delta := 1
 
 	// Round up the width so we don't draw a partial bar.
-	xColMax := math.Round(width/d.binWidth) * d.binWidth
+	xColMax := math.Round(f.Width/binWidth) * binWidth
 
 	xBin := 0
-	xCol := (d.binWidth)/2 + (width-xColMax)/2
+	xCol := (binWidth)/2 + (f.Width-xColMax)/2
 
-	for _, chBins := range bins {
-		for xBin < d.shared.barCount && xBin >= 0 && xCol < xColMax {
-			stop := calculateBar(chBins[xBin]*scale, height, d.cfg.MinimumClamp)
+	for ch, chBins := range bins {
+		for xBin < count && xBin >= 0 && xCol < xColMax {
+			stop := calculateBar(chBins[xBin]*scale, f.Height, f.MinimumClamp)
 
 			// Don't draw if stop is NaN for some reason.
 			if !math.IsNaN(stop) {
-				d.drawBar(cr, xCol, height, stop)
+				colored := f.setBarColor(cr, chBins[xBin]/f.Scale)
+
+				if f.Options.Segments.enabled() {
+					f.drawSegments(cr, xCol, f.Height, stop, f.Height)
+				} else {
+					f.drawBar(cr, xCol, f.Height, stop)
+				}
+
+				if colored {
+					cr.Restore()
+				}
 			}
 
-			xCol += d.binWidth
+			if f.PeakCaps != nil {
+				f.drawPeakCap(cr, f.PeakCaps[ch][xBin], xCol, f.Height, f.Height, -1)
+			}
+
+			xCol += binWidth
 			xBin += delta
 		}
 
@@ -325,12 +680,83 @@ func (d *Drawer) drawHorizontally(width, height float64, cr *cairo.Context) {
 	}
 }
 
-func (d *Drawer) drawBar(cr *cairo.Context, xCol, to, from float64) {
-	cr.MoveTo(d.cfg.Offsets.apply(xCol, d.cfg.round(from)))
-	cr.LineTo(d.cfg.Offsets.apply(xCol, d.cfg.round(to)))
+func (f Frame) drawBar(cr *cairo.Context, xCol, to, from float64) {
+	cr.MoveTo(f.Options.Offsets.apply(xCol, f.Options.round(from)))
+	cr.LineTo(f.Options.Offsets.apply(xCol, f.Options.round(to)))
 	cr.Stroke()
 }
 
+// drawSegments draws the bar from base to tip as discrete segments. Only whole
+// segments are drawn. length is the maximum length of the bar, which is used
+// to determine the level zone of each segment.
+func (f Frame) drawSegments(cr *cairo.Context, xCol, base, tip, length float64) {
+	opts := f.Options.Segments
+	step := opts.Height + opts.Gap
+
+	dir := 1.0
+	if tip < base {
+		dir = -1
+	}
+
+	n := int((math.Abs(tip-base) + opts.Gap) / step)
+	// The source is already the color of the normal zone.
+	zone := 0
+	colored := false
+
+	for i := 0; i < n; i++ {
+		start := float64(i) * step
+
+		if z := opts.zone((start + opts.Height) / length); z != zone {
+			zone = z
+
+			if colored {
+				cr.Restore()
+				colored = false
+			}
+
+			if c := f.zoneColor(z); c != nil {
+				cr.Save()
+				cr.SetSourceRGBA(c[0], c[1], c[2], c[3])
+				colored = true
+			}
+		}
+
+		f.drawBar(cr, xCol, base+dir*(start+opts.Height), base+dir*start)
+	}
+
+	if colored {
+		cr.Restore()
+	}
+}
+
+// zoneColor returns the color of the given level zone of the segments, or nil
+// to use the source.
+func (f Frame) zoneColor(zone int) *CairoColor {
+	switch zone {
+	case 1:
+		return f.WarningColor
+	case 2:
+		return f.CriticalColor
+	default:
+		return nil
+	}
+}
+
+// setBarColor sets the color of the next bar from its value normalized to the
+// scale. It does nothing and returns false unless the ColorMode is
+// ColorAmplitude. Otherwise, the source is saved and must be restored with
+// cr.Restore after the bar is drawn.
+func (f Frame) setBarColor(cr *cairo.Context, value float64) bool {
+	if f.Options.ColorMode != ColorAmplitude {
+		return false
+	}
+
+	c := interpolateColor([]CairoColor{f.QuietColor, f.LoudColor}, value)
+	cr.Save()
+	cr.SetSourceRGBA(c[0], c[1], c[2], c[3])
+	return true
+}
+
 func calculateBar(value, height, clamp float64) float64 {
 	bar := math.Max(math.Min(value, height), clamp) - clamp
 	// Rescale the lost value.
@@ -339,21 +765,31 @@ func calculateBar(value, height, clamp float64) float64 {
 	return height - bar
 }
 
-func (d *Drawer) drawLines(width, height float64, cr *cairo.Context) {
-	bins := d.shared.barBufs
-	ceil := calculateBar(0, height, d.cfg.MinimumClamp)
-	scale := height / d.shared.scale
+// linesRenderer draws DrawLines. It scales the bars into its own buffer, since
+// the bars of a Frame must not be modified.
+type linesRenderer struct {
+	scaled [][]float64
+}
 
-	// Override the bar buffer with the scaled values. I'm unsure why this is
-	// needed instead of doing it all in one loop.
-	for _, ch := range bins {
-		for bar := 0; bar < d.shared.barCount; bar++ {
-			v := calculateBar(ch[bar]*scale, height, d.cfg.MinimumClamp)
+func (r *linesRenderer) Render(cr *cairo.Context, f Frame) {
+	count := len(f.Bars[0])
+	if len(r.scaled) != len(f.Bars) || len(r.scaled[0]) != count {
+		r.scaled = allocBarBufs(count, len(f.Bars))
+	}
+
+	bins := r.scaled
+	ceil := calculateBar(0, f.Height, f.MinimumClamp)
+	scale := f.Height / f.Scale
+
+	// Scale all bars first, since each point is averaged with the next one.
+	for ch, bars := range f.Bars {
+		for bar, v := range bars {
+			v = calculateBar(v*scale, f.Height, f.MinimumClamp)
 			if math.IsNaN(v) {
 				v = ceil
 			}
 
-			ch[bar] = v
+			bins[ch][bar] = v
 		}
 	}
 
@@ -365,10 +801,10 @@ func (d *Drawer) drawLines(width, height float64, cr *cairo.Context) {
 	// without any gaps on either end. Ignore the last bar (-1-1) because it
 	// peaks up for some reason.
 	barCount := math.Min(
-		math.Round(width/d.binWidth),
-		float64((d.shared.barCount-2)*d.channels),
+		math.Round(f.Width/f.Options.binWidth()),
+		float64((count-2)*len(bins)),
 	)
-	binWidth := width / barCount
+	binWidth := f.Width / barCount
 
 	var bar int
 	first := true
@@ -377,7 +813,7 @@ func (d *Drawer) drawLines(width, height float64, cr *cairo.Context) {
 		// If we're iterating backwards, then check the lower bound, or
 		// if we're iterating forwards, then check the upper bound.
 		// Ignore the last bar for the same reason above.
-		for bar >= 0 && bar < d.shared.barCount-1 {
+		for bar >= 0 && bar < count-1 {
 			y := ch[bar]
 
 			if first {
@@ -393,6 +829,14 @@ func (d *Drawer) drawLines(width, height float64, cr *cairo.Context) {
 				cr.LineTo(x, y)
 			}
 
+			if f.setBarColor(cr, (f.Height-y)/f.Height) {
+				// Stroke every segment on its own to color it by its value.
+				px, py := cr.GetCurrentPoint()
+				cr.Stroke()
+				cr.Restore()
+				cr.MoveTo(px, py)
+			}
+
 			x += binWidth
 			bar += delta
 		}
@@ -418,3 +862,172 @@ func quadCurve(t *cairo.Context, p1x, p1y, p2x, p2y float64) {
 
 	t.CurveTo(cp1x, cp1y, cp2x, cp2y, p2x, p2y)
 }
+
+func drawRadial(cr *cairo.Context, f Frame) {
+	opts := f.Options.Radial
+	bins := f.Bars
+	count := len(bins[0])
+
+	cx, cy, inner, outer := opts.ring(f.Width, f.Height)
+	cx, cy = f.Options.Offsets.apply(cx, cy)
+
+	length := outer - inner
+	scale := length / f.Scale
+
+	total := count * len(bins)
+	if total < 2 {
+		return
+	}
+
+	// Angles are clockwise from the top, so shift Cairo's angles (which start
+	// from the right) back by a quarter.
+	angle := (opts.StartAngle - 90) * math.Pi / 180
+	step := (opts.Sweep * math.Pi / 180) / float64(total)
+
+	// Start in the middle of the first bar.
+	angle += step / 2
+
+	// Store the tips of the bars as polar coordinates. A NaN radius means that
+	// the bar should not be drawn.
+	tips := make([][2]float64, 0, total)
+
+	// Flip this to iterate backwards and draw the other channel mirrored, the
+	// same way drawHorizontally does it.
+	delta := 1
+	bar := 0
+
+	for _, ch := range bins {
+		for bar >= 0 && bar < count {
+			stop := calculateBar(ch[bar]*scale, length, f.MinimumClamp)
+			tips = append(tips, [2]float64{angle, inner + f.Options.round(length-stop)})
+
+			angle += step
+			bar += delta
+		}
+
+		delta = -delta
+		bar += delta
+	}
+
+	if !opts.Lines {
+		for _, tip := range tips {
+			// Don't draw if the tip is NaN for some reason.
+			if math.IsNaN(tip[1]) {
+				continue
+			}
+
+			colored := f.setBarColor(cr, (tip[1]-inner)/length)
+
+			cos, sin := math.Cos(tip[0]), math.Sin(tip[0])
+			cr.MoveTo(cx+inner*cos, cy+inner*sin)
+			cr.LineTo(cx+tip[1]*cos, cy+tip[1]*sin)
+			cr.Stroke()
+
+			if colored {
+				cr.Restore()
+			}
+		}
+		return
+	}
+
+	// Convert the polar coordinates to Cartesian ones in place.
+	for i, tip := range tips {
+		r := tip[1]
+		if math.IsNaN(r) {
+			r = inner
+		}
+
+		tips[i] = [2]float64{
+			cx + r*math.Cos(tip[0]),
+			cy + r*math.Sin(tip[0]),
+		}
+	}
+
+	if !opts.full() {
+		cr.MoveTo(tips[0][0], tips[0][1])
+
+		for i := 1; i < len(tips)-1; i++ {
+			// Average out the middle point with the next one for smoothing.
+			next := tips[i+1]
+			quadCurve(cr, tips[i][0], tips[i][1], (tips[i][0]+next[0])/2, (tips[i][1]+next[1])/2)
+		}
+
+		last := tips[len(tips)-1]
+		cr.LineTo(last[0], last[1])
+		cr.Stroke()
+		return
+	}
+
+	// Start from the middle of the last and the first point so the curve
+	// closes smoothly.
+	last := tips[len(tips)-1]
+	cr.MoveTo((last[0]+tips[0][0])/2, (last[1]+tips[0][1])/2)
+
+	for i, tip := range tips {
+		next := tips[(i+1)%len(tips)]
+		quadCurve(cr, tip[0], tip[1], (tip[0]+next[0])/2, (tip[1]+next[1])/2)
+	}
+
+	cr.ClosePath()
+	cr.Stroke()
+}
+
+// triggerHysteresis is the level that the signal must fall below before a
+// rising zero crossing is accepted as the trigger. This prevents noise around
+// zero from moving the trigger around.
+const triggerHysteresis = 0.01
+
+func drawOscilloscope(cr *cairo.Context, f Frame) {
+	samples := f.Samples
+	if len(samples) == 0 || len(samples[0]) < 4 {
+		return
+	}
+
+	// Only draw half of the buffer, so the trigger can be anywhere within the
+	// other half.
+	span := len(samples[0]) / 2
+	start := triggerIndex(samples[0][:span])
+	step := f.Width / float64(span-1)
+
+	gain := f.Options.Oscilloscope.Gain
+	if gain == 0 {
+		gain = 1
+	}
+
+	// Give each channel its own lane.
+	laneHeight := f.Height / float64(len(samples))
+	amplitude := (laneHeight - f.Options.BarWidth) / 2 * gain
+
+	for i, ch := range samples {
+		center := laneHeight*float64(i) + laneHeight/2
+
+		for j, sample := range ch[start : start+span] {
+			x, y := f.Options.Offsets.apply(float64(j)*step, center-sample*amplitude)
+			if j == 0 {
+				cr.MoveTo(x, y)
+			} else {
+				cr.LineTo(x, y)
+			}
+		}
+
+		cr.Stroke()
+	}
+}
+
+// triggerIndex finds the first rising zero crossing in the given samples, so
+// that periodic waves are drawn at the same phase every frame. It returns 0 if
+// there is none.
+func triggerIndex(samples []input.Sample) int {
+	armed := false
+
+	for i, sample := range samples {
+		switch {
+		case sample < -triggerHysteresis:
+			armed = true
+		case armed && sample >= 0:
+			return i
+		}
+	}
+
+	return 0
+}
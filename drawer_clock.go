@@ -0,0 +1,79 @@
+package catnip
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gdk/v3"
+	"github.com/diamondburned/gotk4/pkg/gtk/v3"
+)
+
+// clockSlack is how early in µs a frame can be processed when MaxFrameRate is
+// set, since the frame times jitter around the monitor's refresh rate.
+const clockSlack = 1000
+
+// startClock starts processing the bars on every tick of the frame clock. It
+// must be called in the main thread.
+func (d *Drawer) startClock() {
+	d.clock.running = true
+
+	if d.parent.Mapped() {
+		d.addTick()
+	}
+}
+
+// stopClock stops the frame clock started by startClock. It must be called in
+// the main thread.
+func (d *Drawer) stopClock() {
+	d.clock.running = false
+	d.removeTick()
+}
+
+// addTick adds the tick callback if the clock is running. It is bound to the
+// map signal.
+func (d *Drawer) addTick() {
+	if !d.clock.running || d.clock.id != 0 {
+		return
+	}
+
+	d.clock.last = 0
+	d.clock.next = 0
+	d.clock.id = d.parent.AddTickCallback(d.tick)
+}
+
+// removeTick removes the tick callback. It is bound to the unmap signal.
+func (d *Drawer) removeTick() {
+	if d.clock.id == 0 {
+		return
+	}
+
+	d.parent.RemoveTickCallback(d.clock.id)
+	d.clock.id = 0
+}
+
+func (d *Drawer) tick(_ gtk.Widgetter, frameClock gdk.FrameClocker) bool {
+	now := gdk.BaseFrameClock(frameClock).FrameTime()
+
+	dt := 1 / float64(d.cfg.FrameRate)
+
+	if d.clock.last != 0 {
+		if now < d.clock.next-clockSlack {
+			return true
+		}
+		dt = float64(now-d.clock.last) / 1e6
+	}
+
+	d.clock.last = now
+
+	if max := d.cfg.MaxFrameRate; max > 0 {
+		interval := int64(1e6 / max)
+
+		// Advance from the deadline instead of now, so that the frame rate
+		// averages out to the cap when the refresh rate isn't a multiple of
+		// it. Start over if we've fallen behind.
+		d.clock.next += interval
+		if d.clock.next < now {
+			d.clock.next = now + interval
+		}
+	}
+
+	d.nextFrame(dt)
+	return true
+}
@@ -0,0 +1,73 @@
+package catnip
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+// shadowColor is the default color of the drop shadow.
+var shadowColor = CairoColor{0, 0, 0, 0.5}
+
+// blur is a cached surface that holds a downscaled copy of the layer.
+type blur struct {
+	surface *cairo.Surface
+	context *cairo.Context
+	factor  float64
+	width   int
+	height  int
+}
+
+// update redraws the downscaled copy of the given layer. Scaling it back up
+// blurs it by roughly the given radius.
+func (b *blur) update(layer *cairo.Surface, width, height, radius float64) {
+	factor := math.Max(radius, 1)
+	w := int(math.Ceil(width / factor))
+	h := int(math.Ceil(height / factor))
+
+	if b.surface == nil || b.factor != factor || b.width != w || b.height != h {
+		b.surface = layer.CreateSimilar(cairo.CONTENT_ALPHA, w, h)
+		b.context = cairo.Create(b.surface)
+		b.context.Scale(1/factor, 1/factor)
+		b.factor = factor
+		b.width = w
+		b.height = h
+	}
+
+	b.context.SetOperator(cairo.OPERATOR_SOURCE)
+	b.context.SetSourceSurface(layer, 0, 0)
+	b.context.Paint()
+}
+
+// paint paints the given color through the blurred shape at the given offset.
+// Intensities above 1 paint it more than once.
+func (b *blur) paint(cr *cairo.Context, c CairoColor, x, y, intensity float64) {
+	cr.Save()
+	defer cr.Restore()
+
+	cr.Translate(x, y)
+	cr.Scale(b.factor, b.factor)
+
+	for n := intensity; n > 0; n-- {
+		cr.SetSourceRGBA(c[0], c[1], c[2], c[3]*math.Min(n, 1))
+		cr.MaskSurface(b.surface, 0, 0)
+	}
+}
+
+// drawEffects draws the effects of the layer followed by the layer itself onto
+// the given context. The width and height are the untransformed dimensions.
+func (d *Drawer) drawEffects(cr *cairo.Context, width, height float64) {
+	layer := d.effects.layer.surface
+
+	if opts := d.cfg.Shadow; opts.enabled() {
+		d.effects.shadow.update(layer, width, height, opts.Blur)
+		d.effects.shadow.paint(cr, d.shadow, opts.OffsetX, opts.OffsetY, 1)
+	}
+
+	if opts := d.cfg.Glow; opts.enabled() {
+		d.effects.glow.update(layer, width, height, opts.Radius)
+		d.effects.glow.paint(cr, d.glow, 0, 0, opts.Intensity)
+	}
+
+	d.effects.layer.paint(cr)
+}
@@ -0,0 +1,59 @@
+package catnip
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+// gradientPattern creates the Cairo pattern for the foreground gradient within
+// the given dimensions. It returns nil if there is no gradient.
+func (d *Drawer) gradientPattern(width, height float64) *cairo.Pattern {
+	gradient := d.cfg.Colors.Gradient
+	if gradient == nil || len(gradient.Stops) == 0 {
+		return nil
+	}
+
+	var pattern *cairo.Pattern
+	var err error
+
+	// offset maps the stop offset to the pattern offset. Styles that mirror
+	// their amplitudes around the center need their stops mirrored as well.
+	offset := func(o float64) []float64 { return []float64{o} }
+
+	switch gradient.Kind {
+	case GradientFrequency:
+		pattern, err = cairo.NewPatternLinear(0, 0, width, 0)
+
+	case GradientAmplitude:
+		// Bars grow from the bottom up.
+		pattern, err = cairo.NewPatternLinear(0, height, 0, 0)
+
+		if d.drawsStyle(DrawVerticalBars) {
+			offset = func(o float64) []float64 { return []float64{0.5 - o/2, 0.5 + o/2} }
+		}
+
+	case GradientRadial:
+		cx, cy := width/2, height/2
+		inner, outer := 0.0, math.Hypot(cx, cy)
+
+		if d.drawsStyle(DrawRadial) {
+			_, _, inner, outer = d.cfg.Radial.ring(width, height)
+		}
+
+		pattern, err = cairo.NewPatternRadial(cx, cy, inner, cx, cy, outer)
+	}
+
+	if err != nil || pattern == nil {
+		return nil
+	}
+
+	for _, stop := range gradient.Stops {
+		c := getColor(stop.Color, nil, d.fg)
+		for _, o := range offset(stop.Offset) {
+			pattern.AddColorStopRGBA(o, c[0], c[1], c[2], c[3])
+		}
+	}
+
+	return pattern
+}
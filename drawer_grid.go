@@ -0,0 +1,279 @@
+package catnip
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/diamondburned/gotk4/pkg/pangocairo"
+)
+
+// GridFrequencies is the frequencies in Hz that the grid and spectrogram
+// exports label.
+var GridFrequencies = []float64{
+	20, 50, 100, 200, 500, 1000, 2000, 5000, 10000, 20000,
+}
+
+const (
+	// gridAlpha is the alpha multiplier of the default grid color.
+	gridAlpha = 0.5
+	// gridPadding is the space between a label and its line.
+	gridPadding = 2
+)
+
+// drawGrid draws the grid overlay. Unlike the other draw methods, the context
+// must not be oriented, so that the labels are always upright. The width and
+// height are the untransformed dimensions.
+func (d *Drawer) drawGrid(width, height float64, cr *cairo.Context) {
+	if d.front.count == 0 {
+		return
+	}
+
+	cr.Save()
+	defer cr.Restore()
+
+	cr.SetLineWidth(1)
+	cr.SetLineCap(cairo.LINE_CAP_BUTT)
+	cr.SetSourceRGBA(d.grid[0], d.grid[1], d.grid[2], d.grid[3])
+
+	layout := pangocairo.CreateLayout(cr)
+	if d.font != nil {
+		layout.SetFontDescription(d.font)
+	}
+
+	g := gridPainter{
+		d:      d,
+		cr:     cr,
+		layout: layout,
+		width:  width,
+		height: height,
+	}
+
+	if d.cfg.Grid.DecibelStep > 0 {
+		g.drawDecibels()
+	}
+
+	if d.cfg.Grid.Frequencies {
+		g.drawFrequencies()
+	}
+}
+
+// gridPainter draws the lines and labels of the grid within the oriented
+// space onto the untransformed context.
+type gridPainter struct {
+	d      *Drawer
+	cr     *cairo.Context
+	layout *pango.Layout
+	// width and height are the untransformed dimensions.
+	width  float64
+	height float64
+}
+
+// size returns the dimensions of the oriented space.
+func (g gridPainter) size() (float64, float64) {
+	if g.d.cfg.Orientation.swapsAxes() {
+		return g.height, g.width
+	}
+	return g.width, g.height
+}
+
+// line draws a line between the given points.
+func (g gridPainter) line(x0, y0, x1, y1 float64) {
+	opts := g.d.cfg.DrawOptions
+
+	g.cr.MoveTo(opts.unorient(x0+opts.Offsets.X, y0+opts.Offsets.Y, g.width, g.height))
+	g.cr.LineTo(opts.unorient(x1+opts.Offsets.X, y1+opts.Offsets.Y, g.width, g.height))
+	g.cr.Stroke()
+}
+
+// measure sets the text of the label and returns its dimensions.
+func (g gridPainter) measure(text string) (float64, float64) {
+	g.layout.SetText(text, -1)
+	w, h := g.layout.PixelSize()
+	return float64(w), float64(h)
+}
+
+// label draws the last measured label next to the given point. The label is
+// kept within the bounds of the widget.
+func (g gridPainter) label(x, y, w, h float64) {
+	opts := g.d.cfg.DrawOptions
+
+	x, y = opts.unorient(x+opts.Offsets.X, y+opts.Offsets.Y, g.width, g.height)
+	x = math.Max(math.Min(x+gridPadding, g.width-w-gridPadding), 0)
+	y = math.Max(math.Min(y+gridPadding, g.height-h-gridPadding), 0)
+
+	g.cr.MoveTo(x, y)
+	pangocairo.ShowLayout(g.cr, g.layout)
+}
+
+// decibelLevels returns the ratios of the maximum bar length that each
+// amplitude line is at, from 0 dB downwards. minRatio is the smallest ratio
+// worth drawing.
+func decibelLevels(step, minRatio float64) []float64 {
+	var levels []float64
+
+	for db := 0.0; ; db -= step {
+		ratio := math.Pow(10, db/20)
+		if ratio < minRatio || len(levels) > 100 {
+			return levels
+		}
+		levels = append(levels, ratio)
+	}
+}
+
+func (g gridPainter) drawDecibels() {
+	d := g.d
+	width, height := g.size()
+	step := d.cfg.Grid.DecibelStep
+
+	// Stop once the lines are closer to the base than a label is tall.
+	_, textHeight := g.measure("0 dB")
+
+	switch d.cfg.DrawStyle {
+	case DrawVerticalBars:
+		center := math.Max((height-d.cfg.MinimumClamp)/2, 0)
+
+		for i, ratio := range decibelLevels(step, textHeight/center) {
+			y := calculateBar(ratio*center, center, d.cfg.MinimumClamp)
+
+			g.line(0, y, width, y)
+			g.line(0, height-y, width, height-y)
+
+			w, h := g.measure(formatDecibels(float64(-i) * step))
+			g.label(0, y, w, h)
+		}
+
+	case DrawHorizontalBars, DrawLines:
+		for i, ratio := range decibelLevels(step, textHeight/height) {
+			y := calculateBar(ratio*height, height, d.cfg.MinimumClamp)
+
+			g.line(0, y, width, y)
+
+			w, h := g.measure(formatDecibels(float64(-i) * step))
+			g.label(0, y, w, h)
+		}
+	}
+}
+
+func (g gridPainter) drawFrequencies() {
+	d := g.d
+
+	// Only label the analyzers that know where the frequencies are.
+	analyzer, ok := d.analysis.analyzer.(FrequencyAnalyzer)
+	if !ok {
+		return
+	}
+
+	width, height := g.size()
+	bars := d.front.count
+
+	// last is the position of the last drawn label for each column, so that
+	// overlapping labels can be skipped.
+	var last []float64
+
+	for _, hz := range GridFrequencies {
+		pos := analyzer.BarPosition(hz, bars)
+		if pos < -0.5 || pos > float64(bars)-0.5 {
+			continue
+		}
+
+		w, h := g.measure(FormatFrequency(hz))
+
+		// The extent of the label along the frequency axis, which is vertical
+		// for spectrograms.
+		extent := w
+		if d.cfg.Orientation.swapsAxes() != (d.cfg.DrawStyle == DrawSpectrogram) {
+			extent = h
+		}
+
+		if d.cfg.DrawStyle == DrawSpectrogram {
+			// Spectrograms draw the lowest frequency at the bottom.
+			y := height - (pos+0.5)*height/float64(bars)
+			if len(last) > 0 && math.Abs(last[0]-y) < extent+gridPadding {
+				continue
+			}
+
+			g.line(0, y, width, y)
+			g.label(0, y, w, h)
+			last = []float64{y}
+			continue
+		}
+
+		for i, x := range d.frequencyColumns(width, pos) {
+			if x < 0 || x > width {
+				continue
+			}
+
+			if i < len(last) {
+				if math.Abs(last[i]-x) < extent+gridPadding {
+					continue
+				}
+				last[i] = x
+			} else {
+				last = append(last, x)
+			}
+
+			g.line(x, 0, x, height)
+			g.label(x, height, w, h)
+		}
+	}
+}
+
+// frequencyColumns returns the positions along the frequency axis that the
+// given fractional bar is drawn at. Mirrored styles return one position for
+// each channel. It mirrors the layout of the draw methods.
+func (d *Drawer) frequencyColumns(width, bar float64) []float64 {
+	bars := float64(d.front.count)
+
+	switch d.cfg.DrawStyle {
+	case DrawVerticalBars:
+		xColMax := math.Round(width/d.binWidth) * d.binWidth
+		xCol := d.binWidth/2 + (width-xColMax)/2 + bar*d.binWidth
+		if xCol >= xColMax {
+			return nil
+		}
+		return []float64{xCol}
+
+	case DrawHorizontalBars:
+		xColMax := math.Round(width/d.binWidth) * d.binWidth
+		start := d.binWidth/2 + (width-xColMax)/2
+
+		// The second channel is drawn backwards after the first one.
+		cols := []float64{start + bar*d.binWidth}
+		if d.channels > 1 {
+			cols = append(cols, start+(2*bars-1-bar)*d.binWidth)
+		}
+		return cols
+
+	case DrawLines:
+		// Keep this in sync with drawLines, which ignores the last bar.
+		barCount := math.Min(
+			math.Round(width/d.binWidth),
+			float64((d.front.count-2)*d.channels),
+		)
+		binWidth := width / barCount
+
+		cols := []float64{bar * binWidth}
+		if d.channels > 1 {
+			cols = append(cols, (2*bars-3-bar)*binWidth)
+		}
+		return cols
+
+	default:
+		return nil
+	}
+}
+
+// FormatFrequency formats the frequency in Hz as a short label, such as "1k".
+func FormatFrequency(hz float64) string {
+	if hz >= 1000 {
+		return strconv.FormatFloat(hz/1000, 'f', -1, 64) + "k"
+	}
+	return strconv.FormatFloat(hz, 'f', -1, 64)
+}
+
+func formatDecibels(db float64) string {
+	return fmt.Sprintf("%.3g dB", db)
+}
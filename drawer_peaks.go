@@ -0,0 +1,105 @@
+package catnip
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+// peakCap is the state of a single falling peak marker.
+type peakCap struct {
+	value float64 // normalized to the scale
+	hold  float64 // seconds left before falling
+	speed float64 // normalized units per second
+}
+
+func (d *Drawer) reallocPeakCaps() {
+	if d.cfg.PeakCaps.Thickness <= 0 {
+		return
+	}
+
+	d.analysis.peakCaps = allocPeakCaps(d.cfg.fftSize(), d.channels)
+}
+
+func allocPeakCaps(sampleSize, channels int) [][]peakCap {
+	fullBuf := make([]peakCap, channels*sampleSize)
+	peakCaps := make([][]peakCap, channels)
+
+	for idx := range peakCaps {
+		start := idx * sampleSize
+		end := (idx + 1) * sampleSize
+
+		peakCaps[idx] = fullBuf[start:end]
+	}
+
+	return peakCaps
+}
+
+// updatePeakCaps updates the peak caps to hold the maximum of the current bars
+// and drops them after the hold time. dt is the time since the last update in
+// seconds.
+func (d *Drawer) updatePeakCaps(dt float64) {
+	opts := d.cfg.PeakCaps
+
+	a := &d.analysis
+
+	for ch, caps := range a.peakCaps {
+		bars := a.barBufs[ch]
+
+		for bar := range caps[:a.barCount] {
+			pc := &caps[bar]
+			value := math.Min(bars[bar]/a.scale, 1)
+
+			if value >= pc.value {
+				*pc = peakCap{value: value, hold: opts.HoldTime}
+				continue
+			}
+
+			if pc.hold > 0 {
+				pc.hold -= dt
+				continue
+			}
+
+			pc.speed += opts.Gravity * dt
+			pc.value -= pc.speed * dt
+
+			// Land on the bar instead of falling through it.
+			if pc.value < value {
+				pc.value = value
+				pc.speed = 0
+			}
+		}
+	}
+}
+
+// raisePeakCaps raises the peak caps to the bars that were just analyzed,
+// which might be louder than the bars of any frame if several blocks are
+// analyzed between two frames.
+func (d *Drawer) raisePeakCaps() {
+	a := &d.analysis
+
+	for ch, caps := range a.peakCaps {
+		for bar, v := range a.bars[ch] {
+			if value := math.Min(v/a.scale, 1); value >= caps[bar].value {
+				caps[bar] = peakCap{value: value, hold: d.cfg.PeakCaps.HoldTime}
+			}
+		}
+	}
+}
+
+// drawPeakCap draws the peak cap of the given value. The bar starts at base
+// and grows in the given direction (1 or -1) up to the given length.
+func (f Frame) drawPeakCap(cr *cairo.Context, value, xCol, base, length, dir float64) {
+	stop := calculateBar(value*length, length, f.MinimumClamp)
+	// Don't draw caps that are resting at the base.
+	if math.IsNaN(stop) || stop >= length {
+		return
+	}
+
+	y := base + dir*(length-stop)
+
+	cr.Save()
+	cr.SetSourceRGBA(f.PeakColor[0], f.PeakColor[1], f.PeakColor[2], f.PeakColor[3])
+	f.drawBar(cr, xCol, y+dir*f.Options.PeakCaps.Thickness, y)
+	cr.Restore()
+}
@@ -0,0 +1,233 @@
+package catnip
+
+import (
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+// ColorMap is the color map to draw the spectrogram with.
+type ColorMap uint8
+
+const (
+	// ColorMapViridis is the perceptually uniform blue-green-yellow map.
+	ColorMapViridis ColorMap = iota
+	// ColorMapMagma is the perceptually uniform black-purple-yellow map.
+	ColorMapMagma
+	// ColorMapGrayscale goes from black to white.
+	ColorMapGrayscale
+	// ColorMapCustom uses SpectrogramOptions.Gradient. The background and
+	// foreground colors are used if the gradient is empty.
+	ColorMapCustom
+)
+
+var (
+	viridisStops = []CairoColor{
+		hexColor(0x440154), hexColor(0x482878), hexColor(0x3E4989),
+		hexColor(0x31688E), hexColor(0x26828E), hexColor(0x1F9E89),
+		hexColor(0x35B779), hexColor(0x6ECE58), hexColor(0xB5DE2B),
+		hexColor(0xFDE725),
+	}
+	magmaStops = []CairoColor{
+		hexColor(0x000004), hexColor(0x180F3D), hexColor(0x440F76),
+		hexColor(0x721F81), hexColor(0x9E2F7F), hexColor(0xCD4071),
+		hexColor(0xF1605D), hexColor(0xFD9668), hexColor(0xFECA8D),
+		hexColor(0xFCFDBF),
+	}
+	grayscaleStops = []CairoColor{
+		{0, 0, 0, 1},
+		{1, 1, 1, 1},
+	}
+)
+
+func hexColor(rgb uint32) CairoColor {
+	return CairoColor{
+		float64(rgb>>16&0xFF) / 0xFF,
+		float64(rgb>>8&0xFF) / 0xFF,
+		float64(rgb&0xFF) / 0xFF,
+		1,
+	}
+}
+
+// colorMapStops returns the evenly spaced color stops of the color map. The
+// background and foreground colors are used for an empty custom gradient.
+func colorMapStops(opts SpectrogramOptions, bg, fg CairoColor) []CairoColor {
+	switch opts.ColorMap {
+	case ColorMapViridis:
+		return viridisStops
+	case ColorMapMagma:
+		return magmaStops
+	case ColorMapGrayscale:
+		return grayscaleStops
+	}
+
+	if len(opts.Gradient) == 0 {
+		return []CairoColor{bg, fg}
+	}
+
+	stops := make([]CairoColor, len(opts.Gradient))
+	for i, c := range opts.Gradient {
+		stops[i] = getColor(c, nil, fg)
+	}
+
+	return stops
+}
+
+// interpolateColor linearly interpolates the color at v within the evenly
+// spaced stops. v is clamped to be within [0, 1].
+func interpolateColor(stops []CairoColor, v float64) CairoColor {
+	if len(stops) == 1 || !(v > 0) {
+		return stops[0]
+	}
+	if v >= 1 {
+		return stops[len(stops)-1]
+	}
+
+	pos := v * float64(len(stops)-1)
+	idx := int(pos)
+	frac := pos - float64(idx)
+
+	var c CairoColor
+	for i := range c {
+		c[i] = stops[idx][i] + (stops[idx+1][i]-stops[idx][i])*frac
+	}
+
+	return c
+}
+
+// spectrogramHistory is a ring buffer of past frames. Each frame holds the
+// normalized value of each bar averaged across all channels.
+type spectrogramHistory struct {
+	frames [][]float64
+	bars   int
+	// count is the total number of frames ever pushed. The newest frame is at
+	// (count-1) % len(frames).
+	count uint64
+}
+
+// reset reallocates the ring buffer and drops all frames.
+func (h *spectrogramHistory) reset(length, bars int) {
+	if length < 1 {
+		length = 1
+	}
+
+	h.frames = allocBarBufs(bars, length)
+	h.bars = bars
+	h.count = 0
+}
+
+// push pushes the given bins as the newest frame.
+func (h *spectrogramHistory) push(bins [][]float64, bars int, scale float64) {
+	frame := h.frames[h.count%uint64(len(h.frames))]
+
+	for bar := range frame[:bars] {
+		var sum float64
+		for _, ch := range bins {
+			sum += ch[bar]
+		}
+
+		frame[bar] = sum / float64(len(bins)) / scale
+	}
+
+	h.count++
+}
+
+// ordered appends the frames to dst from the oldest to the newest.
+func (h *spectrogramHistory) ordered(dst [][]float64) [][]float64 {
+	length := uint64(len(h.frames))
+
+	var oldest uint64
+	if h.count > length {
+		oldest = h.count - length
+	}
+
+	for i := oldest; i < h.count; i++ {
+		dst = append(dst, h.frames[i%length])
+	}
+
+	return dst
+}
+
+// spectrogramRenderer draws DrawSpectrogram. It keeps the frames that it has
+// drawn on a surface, so that only the new frames are drawn every time.
+type spectrogramRenderer struct {
+	surface *cairo.Surface
+	context *cairo.Context
+	stops   []CairoColor
+	// fg and bg are the colors that the stops were made from.
+	fg     CairoColor
+	bg     CairoColor
+	length int
+	bars   int
+	drawn  uint64 // number of history frames drawn onto the surface
+}
+
+func (r *spectrogramRenderer) Render(cr *cairo.Context, f Frame) {
+	length := f.Options.Spectrogram.History
+	if length < 1 {
+		length = 1
+	}
+
+	bars := len(f.Bars[0])
+
+	if len(f.History) == 0 || bars == 0 {
+		return
+	}
+
+	// Recreate the surface if the bars were recalculated, which also resets
+	// the history, or if the colors have changed.
+	if r.surface == nil || r.length != length || r.bars != bars || r.drawn > f.HistoryCount ||
+		r.fg != f.Foreground || r.bg != f.Background {
+
+		r.surface = cr.GetTarget().CreateSimilar(cairo.CONTENT_COLOR_ALPHA, length, bars)
+		r.context = cairo.Create(r.surface)
+		// Replace the old columns instead of drawing over them.
+		r.context.SetOperator(cairo.OPERATOR_SOURCE)
+		r.stops = colorMapStops(f.Options.Spectrogram, f.Background, f.Foreground)
+		r.fg = f.Foreground
+		r.bg = f.Background
+		r.length = length
+		r.bars = bars
+		r.drawn = 0
+	}
+
+	// Only draw the frames that are not yet drawn and are still in the
+	// history. Each frame is a column of one pixel wide, and each bar is one
+	// pixel tall.
+	oldest := f.HistoryCount - uint64(len(f.History))
+	if r.drawn < oldest {
+		r.drawn = oldest
+	}
+
+	for ; r.drawn < f.HistoryCount; r.drawn++ {
+		col := int(r.drawn % uint64(length))
+		frame := f.History[r.drawn-oldest]
+
+		for bar, v := range frame[:bars] {
+			c := interpolateColor(r.stops, v)
+			r.context.SetSourceRGBA(c[0], c[1], c[2], c[3])
+			// Draw the lowest frequency at the bottom.
+			r.context.Rectangle(float64(col), float64(bars-bar-1), 1, 1)
+			r.context.Fill()
+		}
+	}
+
+	// The oldest column is right after the newest one. Draw from there to the
+	// end of the surface on the left, then the rest on the right.
+	first := float64(f.HistoryCount % uint64(length))
+
+	cr.Save()
+	defer cr.Restore()
+
+	x, y := f.Options.Offsets.apply(0, 0)
+	cr.Translate(x, y)
+	cr.Scale(f.Width/float64(length), f.Height/float64(bars))
+
+	cr.SetSourceSurface(r.surface, -first, 0)
+	cr.Rectangle(0, 0, float64(length)-first, float64(bars))
+	cr.Fill()
+
+	if first > 0 {
+		cr.SetSourceSurface(r.surface, float64(length)-first, 0)
+		cr.Rectangle(float64(length)-first, 0, first, float64(bars))
+		cr.Fill()
+	}
+}
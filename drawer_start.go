@@ -1,11 +1,11 @@
 package catnip
 
 import (
+	"context"
 	"math"
+	"sync/atomic"
 
 	"github.com/diamondburned/gotk4/pkg/core/glib"
-	"github.com/noriah/catnip/dsp"
-	"github.com/noriah/catnip/fft"
 	"github.com/noriah/catnip/input"
 	"github.com/pkg/errors"
 
@@ -18,7 +18,7 @@ import (
 //
 // The loop will automatically close when the DrawingArea is destroyed.
 func (d *Drawer) Start() (err error) {
-	if d.shared.barBufs != nil {
+	if d.analysis.barBufs != nil {
 		// Panic is reasonable, as calling Start() multiple times (in multiple
 		// goroutines) may cause undefined behaviors.
 		panic("BUG: catnip.Area is already started.")
@@ -39,155 +39,331 @@ func (d *Drawer) Start() (err error) {
 		}
 	}
 
-	d.shared.scale = d.cfg.Scaling.StaticScale
-	if d.shared.scale == 0 {
+	// Capture one hop at a time, so that each hop is analyzed as soon as it
+	// is recorded instead of after a whole SampleSize block.
+	sessionConfig := input.SessionConfig{
+		Device:     d.device,
+		FrameSize:  int(d.channels),
+		SampleSize: d.cfg.hopSize(),
+		SampleRate: d.cfg.SampleRate,
+	}
+
+	d.init()
+
+	// Signal the backend to start listening to the microphone.
+	session, err := d.backend.Start(sessionConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to start the input backend")
+	}
+
+	// Free up the device.
+	d.device = nil
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+
+	// Analyze the frames off the main thread.
+	go d.work(ctx)
+
+	if d.cfg.FrameClock {
+		glib.IdleAdd(d.startClock)
+		defer glib.IdleAdd(d.stopClock)
+	} else {
+		// Periodically queue redraw. Note that this is never a perfect
+		// rounding: inputting 60Hz will trigger a redraw every 16ms, which is
+		// 62.5Hz.
+		ms := 1000 / uint(d.cfg.DrawOptions.FrameRate)
+		timerHandle := glib.TimeoutAddPriority(ms, glib.PriorityDefault, func() bool {
+			d.nextFrame(1 / float64(d.cfg.FrameRate))
+			return true
+		})
+
+		defer glib.SourceRemove(timerHandle)
+	}
+
+	// Write to writeBuf, and we can push it into the ring buffer (see
+	// Process).
+	if err := session.Start(ctx, d.writeBuf, d); err != nil {
+		return errors.Wrap(err, "failed to start input session")
+	}
+
+	return nil
+}
+
+// init allocates the buffers and initializes the processing state.
+func (d *Drawer) init() {
+	a := &d.analysis
+
+	a.scale = d.cfg.Scaling.StaticScale
+	if a.scale == 0 {
 		var (
 			slowMax    = int(d.cfg.Scaling.SlowWindow*d.cfg.SampleRate) / d.cfg.SampleSize * 2
 			fastMax    = int(d.cfg.Scaling.FastWindow*d.cfg.SampleRate) / d.cfg.SampleSize * 2
 			windowData = make([]float64, slowMax+fastMax)
 		)
 
-		d.slowWindow = &catniputil.MovingWindow{
+		a.slowWindow = &catniputil.MovingWindow{
 			Data:     windowData[0:slowMax],
 			Capacity: slowMax,
 		}
 
-		d.fastWindow = &catniputil.MovingWindow{
+		a.fastWindow = &catniputil.MovingWindow{
 			Data:     windowData[slowMax : slowMax+fastMax],
 			Capacity: fastMax,
 		}
 	}
 
-	d.spectrum = dsp.Spectrum{
-		SampleRate: d.cfg.SampleRate,
-		SampleSize: d.cfg.SampleSize,
-		Bins:       make([]dsp.Bin, d.cfg.SampleSize),
-	}
-	d.spectrum.SetSmoothing(d.cfg.SmoothFactor / 100)
-
-	sessionConfig := input.SessionConfig{
-		Device:     d.device,
-		FrameSize:  int(d.channels),
-		SampleSize: d.cfg.SampleSize,
-		SampleRate: d.cfg.SampleRate,
-	}
+	a.smoothing = math.Max(math.Min(d.cfg.SmoothFactor/100, 1), 0)
 
 	// Allocate buffers.
 	d.reallocBarBufs()
-	d.reallocFFTBufs()
-	d.reallocSpectrumOldValues()
-	d.shared.readBuf = input.MakeBuffers(sessionConfig)
-	d.shared.writeBuf = input.MakeBuffers(sessionConfig)
-
-	// Initialize the FFT plans.
-	d.fftPlans = make([]*fft.Plan, d.channels)
-	for idx := range d.fftPlans {
-		plan := fft.Plan{
-			Input:  d.shared.readBuf[idx],
-			Output: d.fftBuf,
-		}
-		plan.Init()
-		d.fftPlans[idx] = &plan
+	d.reallocOldValues()
+	d.reallocPeakCaps()
+	d.writeBuf = allocBarBufs(d.cfg.hopSize(), d.channels)
+	a.history = allocBarBufs(d.cfg.SampleSize, d.channels)
+	a.bars = make([][]float64, d.channels)
+	a.hop = allocBarBufs(d.cfg.hopSize(), d.channels)
+
+	depth := d.cfg.BufferDepth
+	if depth <= 0 {
+		depth = defaultBufferDepth
 	}
+	d.ring.alloc(d.channels, depth*d.cfg.SampleSize)
 
-	// Signal the backend to start listening to the microphone.
-	session, err := d.backend.Start(sessionConfig)
-	if err != nil {
-		return errors.Wrap(err, "failed to start the input backend")
+	if d.cfg.Interpolate {
+		a.tween.prev = allocBarBufs(d.cfg.fftSize(), d.channels)
+		a.tween.next = allocBarBufs(d.cfg.fftSize(), d.channels)
 	}
 
-	// Free up the device.
-	d.device = nil
+	if d.drawsSamples() {
+		a.waveBuf = allocBarBufs(d.cfg.SampleSize, d.channels)
+	}
+}
 
-	// Periodically queue redraw. Note that this is never a perfect rounding:
-	// inputting 60Hz will trigger a redraw every 16ms, which is 62.5Hz.
-	ms := 1000 / uint(d.cfg.DrawOptions.FrameRate)
-	timerHandle := glib.TimeoutAddPriority(ms, glib.PriorityDefault, func() bool {
-		if d.processBars() {
-			d.parent.QueueDraw()
-		}
-		return true
-	})
+// Process pushes the block that the input session has just written into the
+// ring buffer and wakes the worker up to consume it. It never blocks.
+func (d *Drawer) Process() {
+	if atomic.LoadUint32(&d.paused) != 0 {
+		writeZeroBuf(d.writeBuf)
+	}
 
-	defer glib.SourceRemove(timerHandle)
+	d.ring.push(d.writeBuf)
 
-	// Write to writeBuf, and we can copy from write to read (see Process).
-	if err := session.Start(d.ctx, d.shared.writeBuf, d); err != nil {
-		return errors.Wrap(err, "failed to start input session")
+	select {
+	case d.worker.data <- struct{}{}:
+	default:
 	}
+}
 
-	return nil
+// consumeHops analyzes every complete hop in the ring buffer in turn. It must
+// only be called by the worker.
+func (d *Drawer) consumeHops() {
+	a := &d.analysis
+
+	for d.ring.available() >= len(a.hop[0]) {
+		d.ring.consume(a.hop)
+		d.analyzeHop(a.hop)
+	}
 }
 
-// Process processes the internal read buffer and analyzes its spectrum.
-func (d *Drawer) Process() {
-	d.shared.Lock()
-	defer d.shared.Unlock()
+// analyzeHop slides the history by the given hop and analyzes it, so that
+// every one of the overlapping blocks is smoothed into the bars and can raise
+// the peak caps, even if several of them arrive between two frames.
+func (d *Drawer) analyzeHop(hop [][]input.Sample) {
+	a := &d.analysis
 
-	if d.shared.paused {
-		writeZeroBuf(d.shared.readBuf)
-	} else {
-		input.CopyBuffers(d.shared.readBuf, d.shared.writeBuf)
+	slideBuffers(a.history, hop)
+	a.fresh = true
+
+	d.layoutBars()
+	d.analyze(float64(len(hop[0])) / d.cfg.SampleRate)
+
+	if a.peakCaps != nil {
+		d.raisePeakCaps()
 	}
 }
 
-func (d *Drawer) processBars() bool {
+// layoutBars lays the bars out again if the width to draw them in has changed.
+func (d *Drawer) layoutBars() {
+	a := &d.analysis
+
 	d.shared.Lock()
-	defer d.shared.Unlock()
+	cairoWidth := d.shared.cairoWidth
+	d.shared.Unlock()
+
+	if cairoWidth != a.barWidth {
+		a.barWidth = cairoWidth
+		a.barCount = a.analyzer.Recalculate(d.bars(a.barWidth))
+		// Don't trust custom analyzers to stay within the buffers.
+		if max := len(a.barBufs[0]); a.barCount > max {
+			a.barCount = max
+		}
+	}
+}
 
-	d.shared.peak = 0
+// processBars prepares the bars of the analyzed blocks for the given frame. dt
+// is the time since the last frame in seconds. It must only be called by the
+// worker.
+func (d *Drawer) processBars(frame *barFrame, dt float64) {
+	a := &d.analysis
 
-	if d.shared.cairoWidth != d.shared.barWidth {
-		d.shared.barWidth = d.shared.cairoWidth
-		d.shared.barCount = d.spectrum.Recalculate(d.bars(d.shared.barWidth))
-	}
+	a.peak = 0
+	d.layoutBars()
 
-	for idx, buf := range d.shared.barBufs {
-		d.cfg.WindowFn(d.shared.readBuf[idx])
-		d.fftPlans[idx].Execute() // process from readBuf into buf
+	fresh := a.fresh
+	a.fresh = false
 
-		for bIdx := range buf[:d.shared.barCount] {
-			v := d.spectrum.ProcessBin(idx, bIdx, d.fftBuf)
-			buf[bIdx] = v
+	// The hops have already been analyzed as they arrived, so only the
+	// samples of the newest one are left to copy.
+	if fresh && a.waveBuf != nil {
+		input.CopyBuffers(a.waveBuf, a.history)
+	}
+
+	if d.cfg.Interpolate {
+		d.interpolateBars(dt, fresh)
+	}
 
-			if d.shared.peak < v {
-				d.shared.peak = v
+	for _, buf := range a.barBufs {
+		for _, v := range buf[:a.barCount] {
+			if a.peak < v {
+				a.peak = v
 			}
 		}
 	}
 
-	if d.slowWindow != nil {
-		fastMean, _ := d.fastWindow.Update(d.shared.peak)
-		slowMean, slowStddev := d.slowWindow.Update(d.shared.peak)
+	if a.slowWindow != nil {
+		fastMean, _ := a.fastWindow.Update(a.peak)
+		slowMean, slowStddev := a.slowWindow.Update(a.peak)
 
-		if length := d.slowWindow.Len(); length >= d.fastWindow.Cap() {
+		if length := a.slowWindow.Len(); length >= a.fastWindow.Cap() {
 			if math.Abs(fastMean-slowMean) > (d.cfg.Scaling.ResetDeviation * slowStddev) {
 				count := int(float64(length) * d.cfg.Scaling.DumpPercent)
-				slowMean, slowStddev = d.slowWindow.Drop(count)
+				slowMean, slowStddev = a.slowWindow.Drop(count)
 			}
 		}
 
-		d.shared.scale = 1
+		a.scale = 1
 		if t := slowMean + (1.5 * slowStddev); t > 1.0 {
-			d.shared.scale = t
+			a.scale = t
 		}
 	}
 
+	if a.peakCaps != nil {
+		d.updatePeakCaps(dt)
+	}
+
+	frame.copyFrom(a)
+	frame.draw = d.shouldDraw()
+}
+
+// shouldDraw returns true if the analyzed frame should be drawn.
+func (d *Drawer) shouldDraw() bool {
+	a := &d.analysis
+
 	// Draw if peak is over the threshold.
-	if d.shared.peak > peakThreshold {
-		d.shared.quiet = 0
+	if a.peak > peakThreshold {
+		a.quiet = 0
 		return true
 	}
 
 	// If we're not over the threshold, then draw until we're quiet for a while.
-	if d.shared.quiet < quietThreshold {
-		d.shared.quiet++
+	if a.quiet < d.quietFrames() {
+		a.quiet++
 		return true
 	}
 
 	return false
 }
 
+// analyze analyzes the history into the bars, or into the tween target if
+// interpolating. seconds is the time since the last analysis.
+func (d *Drawer) analyze(seconds float64) {
+	a := &d.analysis
+
+	bufs := a.barBufs
+	if d.cfg.Interpolate {
+		bufs = a.tween.next
+	}
+
+	for ch, buf := range bufs {
+		a.bars[ch] = buf[:a.barCount]
+	}
+
+	a.analyzer.Analyze(a.history, a.bars)
+
+	if _, ok := a.analyzer.(smoothingAnalyzer); ok {
+		return
+	}
+
+	// Smooth by the time since the last analysis rather than once per
+	// analysis, so that the smoothing is the same whatever the hop size.
+	smoothing := math.Pow(a.smoothing, seconds*float64(d.cfg.FrameRate))
+
+	for ch, buf := range a.bars {
+		old := a.oldValues[ch]
+
+		for bar, v := range buf {
+			v = old[bar]*smoothing + v*(1-smoothing)
+			old[bar] = v
+			buf[bar] = v
+		}
+	}
+}
+
+// interpolateBars tweens the bars towards the last analyzed block. fresh is
+// true if the block has just arrived.
+func (d *Drawer) interpolateBars(dt float64, fresh bool) {
+	a := &d.analysis
+	tween := &a.tween
+
+	if fresh {
+		// Tween from what's on screen, so that there's no jump if the last
+		// block arrived early.
+		input.CopyBuffers(tween.prev, a.barBufs)
+
+		// The time between blocks is measured in frames, so smooth it out to
+		// not jitter when they don't line up.
+		if period := tween.elapsed + dt; tween.period == 0 {
+			tween.period = period
+		} else {
+			tween.period += (period - tween.period) / 4
+		}
+
+		tween.elapsed = 0
+	}
+
+	tween.elapsed += dt
+
+	t := 1.0
+	if tween.period > 0 {
+		t = math.Min(tween.elapsed/tween.period, 1)
+	}
+
+	for idx, buf := range a.barBufs {
+		prev := tween.prev[idx]
+		next := tween.next[idx]
+
+		for bIdx := range buf[:a.barCount] {
+			buf[bIdx] = prev[bIdx] + (next[bIdx]-prev[bIdx])*t
+		}
+	}
+}
+
+// quietFrames returns the number of frames to keep drawing after the input
+// goes quiet, which is long enough for the trails to fade out.
+func (d *Drawer) quietFrames() int {
+	frames := quietThreshold
+
+	if p := d.persistence(); p > 0 && p < 1 {
+		// Fade out until the trail is less than one 8-bit step.
+		if n := int(math.Ceil(math.Log(1.0/255) / math.Log(p))); n > frames {
+			frames = n
+		}
+	}
+
+	return frames
+}
+
 var zeroSamples = make([]input.Sample, 512)
 
 func writeZeroBuf(buf [][]input.Sample) {
@@ -200,11 +376,11 @@ func writeZeroBuf(buf [][]input.Sample) {
 }
 
 func (d *Drawer) reallocBarBufs() {
-	d.shared.barBufs = allocBarBufs(d.cfg.SampleSize, d.channels)
+	d.analysis.barBufs = allocBarBufs(d.cfg.fftSize(), d.channels)
 }
 
-func (d *Drawer) reallocSpectrumOldValues() {
-	d.spectrum.OldValues = allocBarBufs(d.cfg.SampleSize, d.channels)
+func (d *Drawer) reallocOldValues() {
+	d.analysis.oldValues = allocBarBufs(d.cfg.fftSize(), d.channels)
 }
 
 func allocBarBufs(sampleSize, channels int) [][]float64 {
@@ -224,15 +400,13 @@ func allocBarBufs(sampleSize, channels int) [][]float64 {
 	return barBufs
 }
 
-func (d *Drawer) reallocFFTBufs() {
-	d.fftBuf = make([]complex128, d.cfg.SampleSize/2+1)
-}
-
 // bars calculates the number of bars. It is thread-safe.
 func (d *Drawer) bars(width float64) int {
 	var bars = float64(width) / d.binWidth
 
-	if !d.cfg.Monophonic && d.cfg.DrawStyle != DrawHorizontalBars {
+	// Spectrograms average the channels instead of laying them out, and
+	// custom renderers get them laid out like DrawVerticalBars.
+	if !d.cfg.Monophonic && !d.drawsStyle(DrawHorizontalBars) && !d.drawsStyle(DrawSpectrogram) {
 		bars /= float64(d.channels)
 	}
 
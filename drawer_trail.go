@@ -0,0 +1,40 @@
+package catnip
+
+import "github.com/diamondburned/gotk4/pkg/cairo"
+
+// trail is an offscreen surface that keeps the previously drawn frames and
+// fades them out every frame.
+type trail struct {
+	surface *cairo.Surface
+	context *cairo.Context
+	width   int
+	height  int
+}
+
+// begin fades out the previous frames by the given persistence, which is the
+// ratio of the previous frames to keep. It returns the context to draw the new
+// frame onto. The surface is recreated if the dimensions have changed.
+func (t *trail) begin(target *cairo.Surface, width, height int, persistence float64) *cairo.Context {
+	if t.surface == nil || t.width != width || t.height != height {
+		t.surface = target.CreateSimilar(cairo.CONTENT_COLOR_ALPHA, width, height)
+		t.context = cairo.Create(t.surface)
+		t.width = width
+		t.height = height
+		return t.context
+	}
+
+	// Erase the old frames by a fraction of their alpha. This keeps
+	// transparent backgrounds transparent instead of painting over them.
+	t.context.SetOperator(cairo.OPERATOR_DEST_OUT)
+	t.context.SetSourceRGBA(0, 0, 0, 1-persistence)
+	t.context.Paint()
+	t.context.SetOperator(cairo.OPERATOR_OVER)
+
+	return t.context
+}
+
+// paint paints the trail onto the given context.
+func (t *trail) paint(cr *cairo.Context) {
+	cr.SetSourceSurface(t.surface, 0, 0)
+	cr.Paint()
+}
@@ -0,0 +1,49 @@
+package catnip
+
+import (
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+func drawVectorscope(cr *cairo.Context, f Frame) {
+	samples := f.Samples
+	if len(samples) == 0 || len(samples[0]) == 0 {
+		return
+	}
+
+	opts := f.Options.Vectorscope
+
+	cx, cy := f.Options.Offsets.apply(f.Width/2, f.Height/2)
+	radius := (math.Min(f.Width, f.Height) - f.Options.BarWidth) / 2
+
+	gain := opts.Gain
+	if gain == 0 {
+		gain = 1
+	}
+
+	// Plot the left channel against the right channel. Monophonic input is
+	// plotted against itself.
+	lSamples := samples[0]
+	rSamples := samples[1%len(samples)]
+
+	for i := range lSamples {
+		x, y := lSamples[i], rSamples[i]
+		if opts.MidSide {
+			// Rotate by 45° so that the mid signal is vertical and the side
+			// signal is horizontal.
+			x, y = (x-y)/math.Sqrt2, (x+y)/math.Sqrt2
+		}
+
+		x = cx + x*gain*radius
+		y = cy - y*gain*radius
+
+		if i == 0 {
+			cr.MoveTo(x, y)
+		} else {
+			cr.LineTo(x, y)
+		}
+	}
+
+	cr.Stroke()
+}
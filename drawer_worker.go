@@ -0,0 +1,160 @@
+package catnip
+
+import (
+	"context"
+
+	"github.com/noriah/catnip/input"
+)
+
+// barFrame is an analyzed frame. The worker analyzes into the back frame and
+// publishes it as the middle one, and the main thread swaps the newest middle
+// frame to the front before drawing it. Neither has to wait for the other to
+// finish a frame.
+type barFrame struct {
+	// bars is the value of each bar for each channel. Only the first count
+	// bars are valid.
+	bars  [][]float64
+	count int
+	// samples is a copy of the samples before the window function. Nil if
+	// they're not drawn.
+	samples [][]input.Sample
+	// peakCaps is the value of each peak cap. Nil if disabled.
+	peakCaps [][]float64
+
+	scale float64
+	peak  float64
+	// draw is true if the frame is loud enough to be drawn.
+	draw bool
+}
+
+// initFrames allocates the triple buffer and the wake channels. It must be
+// called in newDrawer, before the worker or the main thread can use them.
+func (d *Drawer) initFrames() {
+	newFrame := func() *barFrame {
+		f := &barFrame{
+			bars:  allocBarBufs(d.cfg.fftSize(), d.channels),
+			scale: 1,
+		}
+		if d.drawsSamples() {
+			f.samples = allocBarBufs(d.cfg.SampleSize, d.channels)
+		}
+		if d.cfg.PeakCaps.Thickness > 0 {
+			f.peakCaps = allocBarBufs(d.cfg.fftSize(), d.channels)
+		}
+		return f
+	}
+
+	d.front = newFrame()
+	d.frames.back = newFrame()
+	d.frames.middle = newFrame()
+	d.worker.wake = make(chan struct{}, 1)
+	d.worker.data = make(chan struct{}, 1)
+}
+
+// copyFrom copies the analyzed state into the frame.
+func (f *barFrame) copyFrom(a *analysisState) {
+	f.count = a.barCount
+	f.scale = a.scale
+	f.peak = a.peak
+
+	for ch, buf := range a.barBufs {
+		copy(f.bars[ch][:a.barCount], buf[:a.barCount])
+	}
+
+	if f.samples != nil {
+		input.CopyBuffers(f.samples, a.waveBuf)
+	}
+
+	for ch, caps := range a.peakCaps {
+		for bar, pc := range caps[:a.barCount] {
+			f.peakCaps[ch][bar] = pc.value
+		}
+	}
+}
+
+// work consumes the samples as they arrive and analyzes a frame whenever one
+// is requested until the context is canceled.
+func (d *Drawer) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.worker.data:
+			// Drain the ring buffer at the rate of the input, so that it only
+			// fills up if the worker falls behind, and so that the history is
+			// current once frames are requested again.
+			d.consumeHops()
+			continue
+		case <-d.worker.wake:
+		}
+
+		d.consumeHops()
+
+		d.worker.Lock()
+		dt := d.worker.dt
+		d.worker.dt = 0
+		d.worker.Unlock()
+
+		d.processBars(d.frames.back, dt)
+		d.publishFrame()
+	}
+}
+
+// requestFrame asks the worker to analyze the next frame dt seconds after the
+// last one. If the worker is still busy, the time adds up to the frame after.
+func (d *Drawer) requestFrame(dt float64) {
+	d.worker.Lock()
+	d.worker.dt += dt
+	d.worker.Unlock()
+
+	select {
+	case d.worker.wake <- struct{}{}:
+	default:
+	}
+}
+
+// publishFrame publishes the back frame as the newest one.
+func (d *Drawer) publishFrame() {
+	d.frames.Lock()
+	d.frames.back, d.frames.middle = d.frames.middle, d.frames.back
+	d.frames.ready = true
+	d.frames.Unlock()
+}
+
+// swapFrame swaps the newest published frame to the front. It returns true if
+// there's a new frame that should be drawn. It must be called in the main
+// thread.
+func (d *Drawer) swapFrame() bool {
+	d.frames.Lock()
+	ready := d.frames.ready
+	if ready {
+		d.front, d.frames.middle = d.frames.middle, d.front
+		d.frames.ready = false
+	}
+	d.frames.Unlock()
+
+	if !ready {
+		return false
+	}
+
+	if d.drawsStyle(DrawSpectrogram) {
+		history := &d.spectrogram.history
+		if history.frames == nil || history.bars != d.front.count {
+			history.reset(d.cfg.Spectrogram.History, d.front.count)
+		}
+		history.push(d.front.bars, d.front.count, d.front.scale)
+	}
+
+	return d.front.draw
+}
+
+// nextFrame swaps in the frame that the worker has analyzed since the last
+// call and draws it, then has the worker analyze the next one. This delays
+// the bars by a frame, but the main thread never waits for the analysis.
+func (d *Drawer) nextFrame(dt float64) {
+	if d.swapFrame() {
+		d.parent.QueueDraw()
+	}
+
+	d.requestFrame(dt)
+}
@@ -0,0 +1,97 @@
+package catnip
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWorkerFrames runs the worker against a fake input session and a fake
+// main thread, which is mostly useful with -race.
+func TestWorkerFrames(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SampleRate = 48000
+	cfg.SampleSize = 1024
+	cfg.FrameRate = 60
+
+	d := NewHeadless(cfg)
+	d.shared.cairoWidth = 320
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		d.work(ctx)
+	}()
+
+	// Feed a 1 kHz sine wave as fast as the ring buffer takes it.
+	go func() {
+		defer wg.Done()
+
+		var n int
+		for ctx.Err() == nil {
+			for _, ch := range d.writeBuf {
+				for i := range ch {
+					ch[i] = math.Sin(2 * math.Pi * 1000 * float64(n+i) / cfg.SampleRate)
+				}
+			}
+			n += len(d.writeBuf[0])
+
+			d.Process()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	var drawn int
+	for i := 0; i < 100; i++ {
+		if d.swapFrame() && d.front.count > 0 {
+			drawn++
+		}
+		d.requestFrame(1 / float64(cfg.FrameRate))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	cancel()
+	wg.Wait()
+
+	if drawn == 0 {
+		t.Fatal("no frame was analyzed")
+	}
+}
+
+// TestWorkerDrainsRing checks that the ring buffer doesn't fill up while no
+// frames are requested, such as while the widget is unmapped.
+func TestWorkerDrainsRing(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SampleSize = 1024
+	cfg.BufferDepth = 2
+
+	d := NewHeadless(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.work(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 10*cfg.BufferDepth; i++ {
+		d.Process()
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	if stats := d.InputStats(); stats.DroppedBlocks > 0 {
+		t.Fatalf("dropped %d blocks without frames", stats.DroppedBlocks)
+	}
+}
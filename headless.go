@@ -0,0 +1,67 @@
+package catnip
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/noriah/catnip/input"
+)
+
+// NewHeadless creates a drawer that is not bound to any widget. Instead of
+// calling Start, feed it samples with Feed, analyze each frame with Advance
+// and draw it with Render. Since there is no style context, the colors default
+// to black on transparent unless they're set in the config.
+func NewHeadless(cfg Config) *Drawer {
+	d := newDrawer(cfg)
+	d.init()
+
+	return d
+}
+
+// Feed slides the history by the given samples and analyzes it as if they came
+// from the input session, which records one hop at a time. Each channel must
+// have the same number of samples. The bars are laid out for the size of the
+// last Render, so Render should be called at least once before feeding. Feed
+// must only be used with drawers created by NewHeadless.
+func (d *Drawer) Feed(samples [][]input.Sample) error {
+	if len(samples) != d.channels {
+		return fmt.Errorf("expected %d channels, got %d", d.channels, len(samples))
+	}
+
+	for _, ch := range samples[1:] {
+		if len(ch) != len(samples[0]) {
+			return fmt.Errorf("expected %d samples, got %d", len(samples[0]), len(ch))
+		}
+	}
+
+	if atomic.LoadUint32(&d.paused) != 0 {
+		samples = allocBarBufs(len(samples[0]), d.channels)
+	}
+
+	d.analyzeHop(samples)
+	return nil
+}
+
+// Advance prepares the bars fed so far as the frame dt seconds after the last
+// one and swaps it in for Render, the same way that a running drawer does on
+// every frame. It returns false if the frame is quiet enough to not need a
+// redraw. Advance must only be used with drawers created by NewHeadless.
+func (d *Drawer) Advance(dt float64) bool {
+	// There is no worker, so prepare the frame and swap it in right away.
+	d.processBars(d.frames.back, dt)
+	d.publishFrame()
+
+	return d.swapFrame()
+}
+
+// Render draws the current frame onto a new image surface of the given size.
+func (d *Drawer) Render(width, height int) *cairo.Surface {
+	surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, width, height)
+
+	cr := cairo.Create(surface)
+	d.draw(cr, width, height, nil)
+	surface.Flush()
+
+	return surface
+}
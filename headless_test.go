@@ -0,0 +1,139 @@
+package catnip
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/noriah/catnip/input"
+)
+
+// headlessConfig returns a config of 64 monophonic vertical bars, each 4
+// pixels wide, from 50 Hz to 10 kHz over a 256 pixels wide frame.
+func headlessConfig() Config {
+	cfg := NewConfig()
+	cfg.SampleSize = 1024
+	cfg.Monophonic = true
+	cfg.MinimumClamp = 0
+	cfg.MinFrequency = 50
+	cfg.MaxFrequency = 10000
+	cfg.BarWidth = 2
+	cfg.SpaceWidth = 1
+	cfg.Scaling = ScalingConfig{
+		SlowWindow:     5,
+		FastWindow:     4,
+		DumpPercent:    0.75,
+		ResetDeviation: 1,
+	}
+	return cfg
+}
+
+// renderSine renders a frame of a sine wave of the given frequency and
+// amplitude, and returns the number of drawn pixels of each bar.
+func renderSine(t *testing.T, hz, amplitude float64) (d *Drawer, coverage []int) {
+	const width, height = 256, 64
+
+	cfg := headlessConfig()
+	d = NewHeadless(cfg)
+	d.Render(width, height)
+
+	block := allocBarBufs(cfg.SampleSize, 1)
+	for i := range block[0] {
+		block[0][i] = amplitude * math.Sin(2*math.Pi*hz*float64(i)/cfg.SampleRate)
+	}
+
+	if err := d.Feed([][]input.Sample{block[0]}); err != nil {
+		t.Fatal("failed to feed:", err)
+	}
+
+	d.Advance(1 / float64(cfg.FrameRate))
+
+	surface := d.Render(width, height)
+	data := surface.Data()
+	stride := surface.Stride()
+
+	coverage = make([]int, width/int(cfg.binWidth()))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// The pixels are native-endian with the alpha in the highest
+			// byte.
+			px := *(*uint32)(unsafe.Pointer(&data[y*stride+x*4]))
+			if px>>24 > 0 {
+				coverage[x/int(cfg.binWidth())]++
+			}
+		}
+	}
+
+	return d, coverage
+}
+
+func TestHeadlessRenderSilence(t *testing.T) {
+	_, coverage := renderSine(t, 1000, 0)
+
+	for bar, n := range coverage {
+		if n > 0 {
+			t.Errorf("bar %d drew %d pixels of silence", bar, n)
+		}
+	}
+}
+
+func TestHeadlessRenderSine(t *testing.T) {
+	d, coverage := renderSine(t, 1000, 0.5)
+
+	loudest := 0
+	for bar, n := range coverage {
+		if n > coverage[loudest] {
+			loudest = bar
+		}
+	}
+
+	if coverage[loudest] == 0 {
+		t.Fatal("nothing was drawn")
+	}
+
+	analyzer := d.analysis.analyzer.(FrequencyAnalyzer)
+	want := analyzer.BarPosition(1000, d.front.count)
+
+	if math.Abs(float64(loudest)-want) > 1 {
+		t.Errorf("the loudest bar is %d, want %.1f; coverage: %v", loudest, want, coverage)
+	}
+}
+
+func TestHeadlessPeakCapsEveryHop(t *testing.T) {
+	cfg := headlessConfig()
+	cfg.HopSize = cfg.SampleSize / 4
+	cfg.PeakCaps.Thickness = 2
+
+	d := NewHeadless(cfg)
+	d.Render(256, 64)
+
+	loud := allocBarBufs(cfg.HopSize, 1)
+	for i := range loud[0] {
+		loud[0][i] = math.Sin(2 * math.Pi * 1000 * float64(i) / cfg.SampleRate)
+	}
+
+	// Slide the loud hop out of the block before the frame, so that only its
+	// own analysis can raise the peak caps.
+	blocks := [][][]input.Sample{loud}
+	for i := 0; i < cfg.SampleSize/cfg.HopSize; i++ {
+		blocks = append(blocks, allocBarBufs(cfg.HopSize, 1))
+	}
+
+	for _, block := range blocks {
+		if err := d.Feed(block); err != nil {
+			t.Fatal("failed to feed:", err)
+		}
+	}
+
+	d.Advance(1 / float64(cfg.FrameRate))
+
+	var highest float64
+	for _, v := range d.front.peakCaps[0][:d.front.count] {
+		highest = math.Max(highest, v)
+	}
+
+	if highest < 0.1 {
+		t.Errorf("the highest peak cap is %g, which missed the loud hop", highest)
+	}
+}
@@ -0,0 +1,150 @@
+package catnip
+
+import (
+	"sort"
+
+	"github.com/noriah/catnip/dsp/window"
+	"github.com/noriah/catnip/fft"
+	"github.com/noriah/catnip/input"
+)
+
+// minResolutionBlock is the shortest block that a band of the multi-resolution
+// analyzer will analyze.
+const minResolutionBlock = 32
+
+// multiResolutionAnalyzer runs FFTs of different lengths over the end of the
+// same block and takes each bar from the band that its frequency falls in.
+type multiResolutionAnalyzer struct {
+	windowFn   window.Function
+	crossovers []float64
+	bands      []resolutionBand
+	// barBands is the index of the band of each bar.
+	barBands []int
+}
+
+// resolutionBand is one FFT of the multi-resolution analyzer. It analyzes the
+// last blockSize samples.
+type resolutionBand struct {
+	spectrum  spectrum
+	blockSize int
+	// gain is the blockGain of blockSize, so that the bars don't jump where
+	// the bands cross over.
+	gain float64
+	used bool
+
+	plan   *fft.Plan
+	input  []float64
+	output []complex128
+}
+
+// NewMultiResolutionAnalyzer creates an Analyzer that splits the frequency
+// range at the Crossovers into bands. The lowest band is analyzed over the
+// whole SampleSize, and each band above it over half as many samples as the
+// one below, so the bass stays precise while the treble reacts quickly. The
+// bars are still laid out along the FrequencyScale.
+func NewMultiResolutionAnalyzer(cfg Config) Analyzer {
+	crossovers := make([]float64, 0, len(cfg.Crossovers))
+	for _, hz := range cfg.Crossovers {
+		if hz > 0 {
+			crossovers = append(crossovers, hz)
+		}
+	}
+	sort.Float64s(crossovers)
+
+	a := &multiResolutionAnalyzer{
+		windowFn:   cfg.WindowFn,
+		crossovers: crossovers,
+		bands:      make([]resolutionBand, len(crossovers)+1),
+		barBands:   make([]int, cfg.fftSize()),
+	}
+
+	for i := range a.bands {
+		blockSize := cfg.SampleSize >> uint(i)
+		if blockSize < minResolutionBlock {
+			blockSize = minResolutionBlock
+		}
+		if blockSize > cfg.SampleSize {
+			blockSize = cfg.SampleSize
+		}
+
+		// Keep the same zero-padding ratio as the lowest band.
+		size := blockSize * cfg.fftSize() / cfg.SampleSize
+
+		band := &a.bands[i]
+		band.spectrum = spectrum{
+			SampleRate:   cfg.SampleRate,
+			SampleSize:   size,
+			BlockSize:    blockSize,
+			Scale:        cfg.FrequencyScale,
+			MinFrequency: cfg.MinFrequency,
+			MaxFrequency: cfg.MaxFrequency,
+			Bins:         make([]spectrumBin, cfg.fftSize()),
+		}
+		band.blockSize = blockSize
+		band.gain = blockGain(cfg.SampleSize, blockSize)
+		band.input = make([]float64, size)
+		band.output = make([]complex128, size/2+1)
+
+		band.plan = &fft.Plan{
+			Input:  band.input,
+			Output: band.output,
+		}
+		band.plan.Init()
+	}
+
+	return a
+}
+
+func (a *multiResolutionAnalyzer) Recalculate(bars int) int {
+	// The lowest band has the finest resolution, so it decides the layout of
+	// the bars for every band.
+	bass := &a.bands[0].spectrum
+	bars = bass.Recalculate(bars)
+	lo, hi := bass.frequencyRange()
+
+	for i := range a.bands[1:] {
+		a.bands[i+1].spectrum.layout(bars, lo, hi)
+	}
+
+	for i := range a.bands {
+		a.bands[i].used = false
+	}
+
+	for bar := 0; bar < bars; bar++ {
+		hz := bass.barFrequency(float64(bar)+0.5, bars, lo, hi)
+		band := sort.SearchFloat64s(a.crossovers, hz)
+
+		a.barBands[bar] = band
+		a.bands[band].used = true
+	}
+
+	return bars
+}
+
+func (a *multiResolutionAnalyzer) Analyze(samples [][]input.Sample, bars [][]float64) {
+	for ch, buf := range bars {
+		samples := samples[ch]
+
+		for i := range a.bands {
+			band := &a.bands[i]
+			if !band.used {
+				continue
+			}
+
+			// Each band only analyzes the newest blockSize samples. They're
+			// windowed in the band's own input to leave the samples alone.
+			copy(band.input, samples[len(samples)-band.blockSize:])
+			a.windowFn(band.input[:band.blockSize])
+			band.plan.Execute()
+		}
+
+		for bar := range buf {
+			band := &a.bands[a.barBands[bar]]
+			buf[bar] = band.spectrum.ProcessBin(bar, band.output) * band.gain
+		}
+	}
+}
+
+func (a *multiResolutionAnalyzer) BarPosition(hz float64, bars int) float64 {
+	return a.bands[0].spectrum.barPosition(hz, bars)
+}
@@ -0,0 +1,136 @@
+package catnip
+
+import (
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+// Renderer draws a frame of the visualizer. Applications can implement their
+// own visualizations and register them with RegisterRenderer.
+type Renderer interface {
+	// Render draws the frame onto the given context. The context is already
+	// transformed into the orientation's coordinate space, and its source is
+	// set to the foreground color or gradient.
+	Render(cr *cairo.Context, frame Frame)
+}
+
+// RendererFunc is a function that implements Renderer.
+type RendererFunc func(cr *cairo.Context, frame Frame)
+
+// Render calls f.
+func (f RendererFunc) Render(cr *cairo.Context, frame Frame) { f(cr, frame) }
+
+// Frame is the frame that a Renderer draws. Its slices are only valid during
+// the Render call and must not be modified.
+type Frame struct {
+	// Width and Height are the dimensions within the orientation's coordinate
+	// space.
+	Width  float64
+	Height float64
+
+	// Bars is the value of each bar for each channel.
+	Bars [][]float64
+	// Samples is the raw samples of each channel. It is only available for
+	// custom renderers and the styles that draw samples.
+	Samples [][]float64
+	// PeakCaps is the value of the peak cap of each bar for each channel,
+	// normalized to the scale. It is nil if the peak caps are disabled.
+	PeakCaps [][]float64
+	// History is the past frames of DrawSpectrogram from the oldest to the
+	// newest, which are the bars averaged across the channels and normalized
+	// to their scale. HistoryCount is the number of frames ever added to it,
+	// so that renderers can tell how many of them are new. History is nil
+	// for the other styles.
+	History      [][]float64
+	HistoryCount uint64
+	// Scale is the value that a bar reaches its maximum length at. Divide the
+	// bars by it to normalize them.
+	Scale float64
+	// Peak is the highest bar value of the frame.
+	Peak float64
+	// MinimumClamp is the length that a bar has to reach to be visible.
+	MinimumClamp float64
+
+	// Foreground and Background are the resolved style colors.
+	Foreground CairoColor
+	Background CairoColor
+	// PeakColor is the color of the peak caps.
+	PeakColor CairoColor
+	// QuietColor and LoudColor are the colors that ColorAmplitude colors the
+	// bars between.
+	QuietColor CairoColor
+	LoudColor  CairoColor
+	// WarningColor and CriticalColor are the colors of the segments in the
+	// upper level zones. Nil uses the source.
+	WarningColor  *CairoColor
+	CriticalColor *CairoColor
+	// Options is the draw options of the Drawer.
+	Options DrawOptions
+}
+
+// styleRenderer is the registered Renderer of a DrawStyle. It keeps the state
+// of its built-in renderer between frames for whoever renders with it
+// directly. Drawers create their own built-in renderer of the style instead,
+// so that they don't share any state.
+type styleRenderer struct {
+	style DrawStyle
+	Renderer
+}
+
+// newStyleRenderer creates the Renderer of the given DrawStyle.
+func newStyleRenderer(style DrawStyle) Renderer {
+	switch style {
+	case DrawHorizontalBars:
+		return RendererFunc(drawHorizontally)
+	case DrawLines:
+		return &linesRenderer{}
+	case DrawRadial:
+		return RendererFunc(drawRadial)
+	case DrawOscilloscope:
+		return RendererFunc(drawOscilloscope)
+	case DrawSpectrogram:
+		return &spectrogramRenderer{}
+	case DrawVectorscope:
+		return RendererFunc(drawVectorscope)
+	default:
+		return RendererFunc(drawVertically)
+	}
+}
+
+// NamedRenderer is a registered Renderer.
+type NamedRenderer struct {
+	Name     string
+	Renderer Renderer
+}
+
+// Renderers is the list of registered renderers. The built-in draw styles are
+// registered first in the order of DrawStyle.
+var Renderers []NamedRenderer
+
+// RegisterRenderer registers a renderer. It should only be called during
+// init. It panics if the name is already registered.
+func RegisterRenderer(name string, r Renderer) {
+	if FindRenderer(name) != nil {
+		panic("catnip: renderer " + name + " is already registered")
+	}
+
+	Renderers = append(Renderers, NamedRenderer{Name: name, Renderer: r})
+}
+
+// FindRenderer finds a registered renderer by its name. It returns nil if
+// there is none. The renderers of the built-in styles keep state between
+// frames, such as what DrawSpectrogram has already drawn, so they must not be
+// rendered with concurrently.
+func FindRenderer(name string) Renderer {
+	for _, r := range Renderers {
+		if r.Name == name {
+			return r.Renderer
+		}
+	}
+	return nil
+}
+
+func init() {
+	for style := DrawVerticalBars; style <= DrawVectorscope; style++ {
+		RegisterRenderer(style.String(), &styleRenderer{style, newStyleRenderer(style)})
+	}
+}
@@ -0,0 +1,80 @@
+package catnip
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+)
+
+func TestRegisterRendererDuplicate(t *testing.T) {
+	renderers := Renderers
+	defer func() { Renderers = renderers }()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a built-in name again did not panic")
+		}
+	}()
+
+	RegisterRenderer(DrawVerticalBars.String(), RendererFunc(func(*cairo.Context, Frame) {}))
+}
+
+func TestStyleRenderersKeepBars(t *testing.T) {
+	surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, 64, 32)
+	cr := cairo.Create(surface)
+
+	bars := [][]float64{
+		{0.1, 0.5, 1, 0.5, 0.1, 0},
+		{0, 0.2, 0.4, 0.6, 0.8, 1},
+	}
+
+	for style := DrawVerticalBars; style <= DrawVectorscope; style++ {
+		t.Run(style.String(), func(t *testing.T) {
+			frame := Frame{
+				Width:   64,
+				Height:  32,
+				Bars:    [][]float64{append([]float64(nil), bars[0]...), append([]float64(nil), bars[1]...)},
+				Samples: [][]float64{{0, 0.5, 0, -0.5}, {0, -0.5, 0, 0.5}},
+				Scale:   1,
+				Options: NewConfig().DrawOptions,
+			}
+			frame.History = frame.Bars
+			frame.HistoryCount = 2
+
+			FindRenderer(style.String()).Render(cr, frame)
+
+			if !reflect.DeepEqual(frame.Bars, bars) {
+				t.Errorf("bars were modified into %v", frame.Bars)
+			}
+		})
+	}
+}
+
+func TestFindRendererKeepsState(t *testing.T) {
+	surface := cairo.CreateImageSurface(cairo.FORMAT_ARGB32, 64, 32)
+	cr := cairo.Create(surface)
+
+	history := [][]float64{{0.1, 0.5, 1}, {1, 0.5, 0.1}}
+	frame := Frame{
+		Width:        64,
+		Height:       32,
+		Bars:         history[1:],
+		History:      history,
+		HistoryCount: 2,
+		Scale:        1,
+		Options:      NewConfig().DrawOptions,
+	}
+
+	r := FindRenderer(DrawSpectrogram.String())
+	r.Render(cr, frame)
+
+	if r != FindRenderer(DrawSpectrogram.String()) {
+		t.Fatal("found a different renderer the second time")
+	}
+
+	spectrogram := r.(*styleRenderer).Renderer.(*spectrogramRenderer)
+	if spectrogram.drawn != frame.HistoryCount {
+		t.Errorf("the renderer has drawn %d of %d frames", spectrogram.drawn, frame.HistoryCount)
+	}
+}
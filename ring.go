@@ -0,0 +1,127 @@
+package catnip
+
+import (
+	"sync/atomic"
+
+	"github.com/noriah/catnip/input"
+)
+
+// defaultBufferDepth is the number of input blocks that the ring buffer holds
+// if Config.BufferDepth is 0.
+const defaultBufferDepth = 4
+
+// InputStats is the statistics of the buffer between the input session and
+// the analyzer.
+type InputStats struct {
+	// Overruns is the number of times that the buffer filled up because the
+	// analyzer couldn't keep up.
+	Overruns uint64
+	// DroppedBlocks is the number of input blocks that were dropped because
+	// the buffer was full.
+	DroppedBlocks uint64
+}
+
+// sampleRing is a single-producer single-consumer ring buffer of samples. The
+// input session pushes blocks into it and the worker consumes them, and
+// neither ever waits on the other.
+type sampleRing struct {
+	// The counters are accessed atomically, so keep them 64-bit aligned at
+	// the start of the struct.
+
+	// written and read are the total number of samples pushed and consumed
+	// for each channel.
+	written  uint64
+	read     uint64
+	overruns uint64
+	dropped  uint64
+
+	bufs [][]input.Sample
+	size uint64
+	// overrun is true if the last block was dropped. It is only used by the
+	// producer.
+	overrun bool
+}
+
+// alloc allocates the ring buffer. It must be called before the producer and
+// consumer start.
+func (r *sampleRing) alloc(channels, size int) {
+	r.bufs = allocBarBufs(size, channels)
+	r.size = uint64(size)
+}
+
+// push copies the block into the ring buffer, or drops it if there's no room.
+// It must only be called by the producer.
+func (r *sampleRing) push(block [][]input.Sample) {
+	if len(block) == 0 {
+		return
+	}
+
+	n := uint64(len(block[0]))
+	written := atomic.LoadUint64(&r.written)
+
+	if written+n-atomic.LoadUint64(&r.read) > r.size {
+		// Count each run of dropped blocks as one overrun.
+		if !r.overrun {
+			atomic.AddUint64(&r.overruns, 1)
+			r.overrun = true
+		}
+		atomic.AddUint64(&r.dropped, 1)
+		return
+	}
+
+	r.overrun = false
+
+	start := written % r.size
+	for ch, samples := range block {
+		n := copy(r.bufs[ch][start:], samples)
+		copy(r.bufs[ch], samples[n:])
+	}
+
+	// Publish the samples only after they're written.
+	atomic.StoreUint64(&r.written, written+n)
+}
+
+// available returns the number of samples that can be consumed.
+func (r *sampleRing) available() int {
+	return int(atomic.LoadUint64(&r.written) - atomic.LoadUint64(&r.read))
+}
+
+// consume moves the next samples of each channel into dst, which is as long
+// as the number of samples to consume. There must be that many available. It
+// must only be called by the consumer.
+func (r *sampleRing) consume(dst [][]input.Sample) {
+	read := atomic.LoadUint64(&r.read)
+
+	start := read % r.size
+	for ch, samples := range dst {
+		n := copy(samples, r.bufs[ch][start:])
+		copy(samples[n:], r.bufs[ch])
+	}
+
+	// Free the samples only after they're read.
+	atomic.StoreUint64(&r.read, read+uint64(len(dst[0])))
+}
+
+// stats returns the statistics of the ring buffer.
+func (r *sampleRing) stats() InputStats {
+	return InputStats{
+		Overruns:      atomic.LoadUint64(&r.overruns),
+		DroppedBlocks: atomic.LoadUint64(&r.dropped),
+	}
+}
+
+// slideBuffers slides each channel of the history back by the length of the
+// block and appends the block.
+func slideBuffers(history, block [][]input.Sample) {
+	for ch, samples := range history {
+		block := block[ch]
+
+		if len(block) >= len(samples) {
+			copy(samples, block[len(block)-len(samples):])
+			continue
+		}
+
+		n := copy(samples, samples[len(block):])
+		copy(samples[n:], block)
+	}
+}
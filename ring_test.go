@@ -0,0 +1,177 @@
+package catnip
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/noriah/catnip/input"
+)
+
+// ringOp is a push of a block or a consume of a hop.
+type ringOp struct {
+	push    []input.Sample // nil to consume
+	consume int
+	// want is the consumed hop.
+	want []input.Sample
+}
+
+func pushOp(samples ...input.Sample) ringOp { return ringOp{push: samples} }
+
+func consumeOp(want ...input.Sample) ringOp { return ringOp{consume: len(want), want: want} }
+
+func TestSampleRing(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		ops       []ringOp
+		available int
+		stats     InputStats
+	}{
+		{
+			name: "fifo",
+			size: 8,
+			ops: []ringOp{
+				pushOp(1, 2, 3),
+				pushOp(4, 5, 6),
+				consumeOp(1, 2),
+				consumeOp(3, 4),
+			},
+			available: 2,
+		},
+		{
+			name: "wraparound",
+			size: 4,
+			ops: []ringOp{
+				pushOp(1, 2, 3),
+				consumeOp(1, 2, 3),
+				// Written at 3, 0 and 1.
+				pushOp(4, 5, 6),
+				consumeOp(4, 5),
+				// Written at 2, 3 and 0.
+				pushOp(7, 8, 9),
+				consumeOp(6, 7, 8, 9),
+			},
+		},
+		{
+			name: "exactly full",
+			size: 4,
+			ops: []ringOp{
+				pushOp(1, 2),
+				pushOp(3, 4),
+				consumeOp(1, 2, 3, 4),
+			},
+		},
+		{
+			name: "full ring drops blocks",
+			size: 4,
+			ops: []ringOp{
+				pushOp(1, 2, 3),
+				pushOp(4, 5),
+				pushOp(6, 7),
+				consumeOp(1, 2),
+				// There's room again, which ends the overrun.
+				pushOp(8, 9),
+				pushOp(10, 11),
+				consumeOp(3, 8, 9),
+				pushOp(12, 13),
+			},
+			available: 2,
+			stats:     InputStats{Overruns: 2, DroppedBlocks: 3},
+		},
+		{
+			name: "hops across blocks",
+			size: 8,
+			ops: []ringOp{
+				pushOp(1, 2, 3, 4, 5),
+				consumeOp(1, 2, 3),
+				pushOp(6, 7, 8, 9, 10),
+				consumeOp(4, 5, 6),
+				consumeOp(7, 8, 9),
+			},
+			available: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var r sampleRing
+			r.alloc(2, test.size)
+
+			for i, op := range test.ops {
+				if op.push != nil {
+					r.push(stereo(op.push))
+					continue
+				}
+
+				if available := r.available(); available < op.consume {
+					t.Fatalf("op %d: only %d samples available, need %d", i, available, op.consume)
+				}
+
+				got := allocBarBufs(op.consume, 2)
+				r.consume(got)
+
+				if want := stereo(op.want); !reflect.DeepEqual(got, want) {
+					t.Fatalf("op %d: consumed %v, want %v", i, got, want)
+				}
+			}
+
+			if available := r.available(); available != test.available {
+				t.Errorf("%d samples available, want %d", available, test.available)
+			}
+
+			if stats := r.stats(); stats != test.stats {
+				t.Errorf("stats are %+v, want %+v", stats, test.stats)
+			}
+		})
+	}
+}
+
+func TestSlideBuffers(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []input.Sample
+		block   []input.Sample
+		want    []input.Sample
+	}{
+		{
+			name:    "shorter block",
+			history: []input.Sample{1, 2, 3, 4},
+			block:   []input.Sample{5, 6},
+			want:    []input.Sample{3, 4, 5, 6},
+		},
+		{
+			name:    "same length",
+			history: []input.Sample{1, 2, 3},
+			block:   []input.Sample{4, 5, 6},
+			want:    []input.Sample{4, 5, 6},
+		},
+		{
+			name:    "longer block",
+			history: []input.Sample{1, 2},
+			block:   []input.Sample{3, 4, 5},
+			want:    []input.Sample{4, 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			history := stereo(test.history)
+			slideBuffers(history, stereo(test.block))
+
+			if want := stereo(test.want); !reflect.DeepEqual(history, want) {
+				t.Errorf("got %v, want %v", history, want)
+			}
+		})
+	}
+}
+
+// stereo returns the samples as the left channel, and the negated samples as
+// the right channel.
+func stereo(samples []input.Sample) [][]input.Sample {
+	bufs := allocBarBufs(len(samples), 2)
+	for i, v := range samples {
+		bufs[0][i] = v
+		bufs[1][i] = -v
+	}
+	return bufs
+}
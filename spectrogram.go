@@ -0,0 +1,129 @@
+package catnip
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/noriah/catnip/input"
+)
+
+// Spectrogram analyzes a whole track block by block for offline use. Unlike
+// DrawSpectrogram, it keeps every block and has no smoothing. Each block is a
+// column, with time going from the left to the right and the lowest frequency
+// at the bottom.
+type Spectrogram struct {
+	cfg      Config
+	analyzer Analyzer
+	// history is the last SampleSize samples of each channel, which is what
+	// each column analyzes.
+	history [][]input.Sample
+	// bars is the scratch bars of each channel.
+	bars [][]float64
+
+	columns [][]float64
+	max     float64
+}
+
+// NewSpectrogram creates a spectrogram with the given number of bars. It uses
+// the analyzer, window function, sample and FFT sizes and frequency settings
+// of the config.
+func NewSpectrogram(cfg Config, bars int) *Spectrogram {
+	// Analyzers that smooth the bars themselves shouldn't smooth them here.
+	analyzerCfg := cfg
+	analyzerCfg.SmoothFactor = 0
+
+	s := &Spectrogram{
+		cfg:      cfg,
+		analyzer: analyzerCfg.newAnalyzer(),
+	}
+
+	bars = s.analyzer.Recalculate(bars)
+	s.bars = allocBarBufs(bars, 1)
+
+	return s
+}
+
+// Bars returns the number of bars, which might be less than what was asked
+// for if the sample size is too small.
+func (s *Spectrogram) Bars() int {
+	return len(s.bars[0])
+}
+
+// Len returns the number of blocks added.
+func (s *Spectrogram) Len() int {
+	return len(s.columns)
+}
+
+// BarPosition returns the fractional bar that the given frequency falls on.
+// Frequencies out of the range are out of [-0.5, Bars()-0.5]. It returns NaN
+// if the analyzer is not a FrequencyAnalyzer.
+func (s *Spectrogram) BarPosition(hz float64) float64 {
+	analyzer, ok := s.analyzer.(FrequencyAnalyzer)
+	if !ok {
+		return math.NaN()
+	}
+	return analyzer.BarPosition(hz, s.Bars())
+}
+
+// Add slides the next block of samples into the last SampleSize samples and
+// analyzes them as a column. The block is usually a hop long, but it can have
+// any number of samples as long as all channels have the same number. The
+// channels are averaged.
+func (s *Spectrogram) Add(samples [][]input.Sample) {
+	if len(s.history) != len(samples) {
+		s.history = allocBarBufs(s.cfg.SampleSize, len(samples))
+		s.bars = allocBarBufs(s.Bars(), len(samples))
+	}
+
+	slideBuffers(s.history, samples)
+	s.analyzer.Analyze(s.history, s.bars)
+
+	column := make([]float64, s.Bars())
+	for _, ch := range s.bars {
+		for bar, v := range ch {
+			column[bar] += v / float64(len(samples))
+		}
+	}
+
+	for _, v := range column {
+		if v > s.max {
+			s.max = v
+		}
+	}
+
+	s.columns = append(s.columns, column)
+}
+
+// Image draws the spectrogram with the color map of the config. The bars are
+// drawn in dB relative to the loudest one, and anything more than the given
+// dynamic range below it gets the lowest color.
+func (s *Spectrogram) Image(dynamicRange float64) *image.NRGBA {
+	stops := colorMapStops(
+		s.cfg.Spectrogram,
+		getColor(s.cfg.Colors.Background, nil, CairoColor{0, 0, 0, 1}),
+		getColor(s.cfg.Colors.Foreground, nil, CairoColor{1, 1, 1, 1}),
+	)
+
+	bars := s.Bars()
+	img := image.NewNRGBA(image.Rect(0, 0, len(s.columns), bars))
+
+	for x, column := range s.columns {
+		for bar, v := range column {
+			level := 0.0
+			if v > 0 && s.max > 0 {
+				level = 1 + 20*math.Log10(v/s.max)/dynamicRange
+			}
+
+			c := interpolateColor(stops, level)
+			img.SetNRGBA(x, bars-bar-1, color.NRGBA{
+				R: uint8(c[0]*0xFF + 0.5),
+				G: uint8(c[1]*0xFF + 0.5),
+				B: uint8(c[2]*0xFF + 0.5),
+				A: uint8(c[3]*0xFF + 0.5),
+			})
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,24 @@
+package catnip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpectrogramBarPosition(t *testing.T) {
+	s := NewSpectrogram(NewConfig(), 64)
+
+	// The default layout spans 60 Hz to 8 kHz, so the labels between them
+	// must be on the bars, in order.
+	last := math.Inf(-1)
+	for _, hz := range []float64{100, 200, 500, 1000, 2000, 5000} {
+		pos := s.BarPosition(hz)
+		if math.IsNaN(pos) || pos < -0.5 || pos > float64(s.Bars())-0.5 {
+			t.Errorf("%g Hz is at bar %g, want within the %d bars", hz, pos, s.Bars())
+		}
+		if pos <= last {
+			t.Errorf("%g Hz is at bar %g, which isn't above the last label", hz, pos)
+		}
+		last = pos
+	}
+}
@@ -0,0 +1,182 @@
+package catnip
+
+import (
+	"math"
+)
+
+// FrequencyScale is the distribution of the bars along the frequency axis.
+type FrequencyScale uint8
+
+const (
+	// ScaleLogarithmic gives each octave the same number of bars.
+	ScaleLogarithmic FrequencyScale = iota
+	// ScaleLinear gives each bar the same range of frequencies.
+	ScaleLinear
+	// ScaleMel distributes the bars by the mel scale, which is linear at low
+	// frequencies and logarithmic at high frequencies.
+	ScaleMel
+	// ScaleBark distributes the bars by the Bark scale of critical bands.
+	ScaleBark
+)
+
+// toScale converts the frequency in Hz to the scale's unit.
+func (s FrequencyScale) toScale(hz float64) float64 {
+	switch s {
+	case ScaleLinear:
+		return hz
+	case ScaleMel:
+		return 2595 * math.Log10(1+hz/700)
+	case ScaleBark:
+		// Traunmüller's formula.
+		return 26.81*hz/(1960+hz) - 0.53
+	default:
+		return math.Log(hz)
+	}
+}
+
+// fromScale converts the scale's unit back to the frequency in Hz.
+func (s FrequencyScale) fromScale(v float64) float64 {
+	switch s {
+	case ScaleLinear:
+		return v
+	case ScaleMel:
+		return 700 * (math.Pow(10, v/2595) - 1)
+	case ScaleBark:
+		return 1960 * (v + 0.53) / (26.28 - v)
+	default:
+		return math.Exp(v)
+	}
+}
+
+// spectrum maps the FFT output onto the bars. It replaces dsp.Spectrum, which
+// always spans the whole FFT range.
+type spectrum struct {
+	SampleRate float64
+	SampleSize int // the number of samples that the FFT is done over
+	// BlockSize is the number of samples in each block before it's
+	// zero-padded to SampleSize; 0 means SampleSize.
+	BlockSize int
+	Scale     FrequencyScale
+	// MinFrequency and MaxFrequency are the frequency range in Hz. They are
+	// clamped to the range that the FFT can resolve; zero means no limit.
+	MinFrequency float64
+	MaxFrequency float64
+	// Bins should be at least SampleSize/2 long.
+	Bins []spectrumBin
+}
+
+// spectrumBin is the range of FFT bins that a bar covers.
+type spectrumBin struct {
+	floor int // inclusive
+	ceil  int // exclusive
+}
+
+// frequencyRange returns the frequency range to distribute the bars over,
+// which is within the range that the FFT can resolve. An empty or inverted
+// range falls back to the whole range.
+func (sp *spectrum) frequencyRange() (lo, hi float64) {
+	resolution := sp.SampleRate / float64(sp.SampleSize)
+	nyquist := sp.SampleRate / 2
+
+	lo = math.Min(math.Max(sp.MinFrequency, resolution), nyquist)
+	hi = nyquist
+	if sp.MaxFrequency > 0 {
+		hi = math.Min(math.Max(sp.MaxFrequency, resolution), nyquist)
+	}
+	if hi <= lo {
+		lo, hi = resolution, nyquist
+	}
+
+	return
+}
+
+// Recalculate distributes the given number of bars over the frequency range.
+// It returns the actual number of bars, which is clamped to what the FFT can
+// provide.
+func (sp *spectrum) Recalculate(bars int) int {
+	switch {
+	case bars > sp.SampleSize/2:
+		bars = sp.SampleSize / 2
+	case bars < 1:
+		bars = 1
+	}
+
+	lo, hi := sp.frequencyRange()
+	sp.layout(bars, lo, hi)
+
+	return bars
+}
+
+// layout distributes the bars over the given frequency range, which might be
+// wider than what the FFT can resolve. Bins must be at least bars long.
+func (sp *spectrum) layout(bars int, lo, hi float64) {
+	maxBin := sp.SampleSize / 2
+
+	for bar := 0; bar < bars; bar++ {
+		floor := sp.fftBin(sp.barFrequency(float64(bar), bars, lo, hi))
+		ceil := sp.fftBin(sp.barFrequency(float64(bar+1), bars, lo, hi))
+
+		// Never use the DC bin, and always cover at least one bin. Narrow bars
+		// at the low end might end up sharing the same bin.
+		if floor < 1 {
+			floor = 1
+		}
+		if floor > maxBin {
+			floor = maxBin
+		}
+		if ceil <= floor {
+			ceil = floor + 1
+		}
+
+		sp.Bins[bar] = spectrumBin{
+			floor: floor,
+			ceil:  ceil,
+		}
+	}
+}
+
+// barFrequency returns the frequency at the given fractional bar index of the
+// bars spread over lo to hi, where the lower edge of the first bar is 0.
+func (sp *spectrum) barFrequency(bar float64, bars int, lo, hi float64) float64 {
+	scaleLo := sp.Scale.toScale(lo)
+	scaleHi := sp.Scale.toScale(hi)
+
+	return sp.Scale.fromScale(scaleLo + (scaleHi-scaleLo)*bar/float64(bars))
+}
+
+// fftBin returns the index of the FFT bin closest to the given frequency.
+func (sp *spectrum) fftBin(hz float64) int {
+	return int(math.Round(hz * float64(sp.SampleSize) / sp.SampleRate))
+}
+
+// ProcessBin calculates the magnitude of the given bar from the FFT output.
+func (sp *spectrum) ProcessBin(idx int, fft []complex128) float64 {
+	bin := sp.Bins[idx]
+
+	var power float64
+	for _, c := range fft[bin.floor:bin.ceil] {
+		power += real(c)*real(c) + imag(c)*imag(c)
+	}
+
+	// Zero-padding spreads the same power over more bins.
+	if sp.BlockSize > 0 {
+		power *= float64(sp.BlockSize) / float64(sp.SampleSize)
+	}
+
+	// Use the root of the total power, so that wider bars do not overshadow
+	// narrower ones as much as summing the magnitudes would.
+	return math.Sqrt(power)
+}
+
+// barPosition returns the fractional index of the bar that the given
+// frequency falls on, where each bar is centered on its integer index. bars is
+// the number of bars that Recalculate returned. Frequencies out of the range
+// are out of [-0.5, bars-0.5].
+func (sp *spectrum) barPosition(hz float64, bars int) float64 {
+	lo, hi := sp.frequencyRange()
+	scaleLo := sp.Scale.toScale(lo)
+	scaleHi := sp.Scale.toScale(hi)
+
+	pos := (sp.Scale.toScale(hz) - scaleLo) / (scaleHi - scaleLo)
+	return pos*float64(bars) - 0.5
+}
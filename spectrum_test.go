@@ -0,0 +1,94 @@
+package catnip
+
+import (
+	"math"
+	"testing"
+)
+
+var frequencyScales = []struct {
+	name  string
+	scale FrequencyScale
+}{
+	{"logarithmic", ScaleLogarithmic},
+	{"linear", ScaleLinear},
+	{"mel", ScaleMel},
+	{"bark", ScaleBark},
+}
+
+func TestFrequencyScaleInverse(t *testing.T) {
+	for _, test := range frequencyScales {
+		t.Run(test.name, func(t *testing.T) {
+			for _, hz := range []float64{20, 100, 440, 1000, 4000, 16000, 24000} {
+				got := test.scale.fromScale(test.scale.toScale(hz))
+				if math.Abs(got-hz) > 1e-6*hz {
+					t.Errorf("%g Hz round-trips to %g Hz", hz, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSpectrumFrequencyRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		min    float64
+		max    float64
+		lo, hi float64
+	}{
+		{"unset", 0, 0, 46.875, 24000},
+		{"within", 100, 10000, 100, 10000},
+		{"max above nyquist", 100, 30000, 100, 24000},
+		{"min above nyquist", 30000, 0, 46.875, 24000},
+		{"inverted", 10000, 100, 46.875, 24000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sp := spectrum{
+				SampleRate:   48000,
+				SampleSize:   1024,
+				MinFrequency: test.min,
+				MaxFrequency: test.max,
+			}
+
+			lo, hi := sp.frequencyRange()
+			if lo != test.lo || hi != test.hi {
+				t.Errorf("range is %g to %g Hz, want %g to %g Hz", lo, hi, test.lo, test.hi)
+			}
+		})
+	}
+}
+
+func TestSpectrumBarPosition(t *testing.T) {
+	for _, test := range frequencyScales {
+		t.Run(test.name, func(t *testing.T) {
+			sp := spectrum{
+				SampleRate:   48000,
+				SampleSize:   4096,
+				Scale:        test.scale,
+				MinFrequency: 30,
+				MaxFrequency: 16000,
+				Bins:         make([]spectrumBin, 4096),
+			}
+
+			bars := sp.Recalculate(64)
+			lo, hi := sp.frequencyRange()
+
+			// The edges of the range are the outer edges of the first and
+			// last bars, and each bar is centered on its index.
+			if pos := sp.barPosition(lo, bars); math.Abs(pos+0.5) > 1e-9 {
+				t.Errorf("%g Hz is at bar %g, want -0.5", lo, pos)
+			}
+			if pos := sp.barPosition(hi, bars); math.Abs(pos-float64(bars)+0.5) > 1e-9 {
+				t.Errorf("%g Hz is at bar %g, want %d.5", hi, pos, bars-1)
+			}
+
+			for bar := 0; bar < bars; bar++ {
+				hz := sp.barFrequency(float64(bar)+0.5, bars, lo, hi)
+				if pos := sp.barPosition(hz, bars); math.Abs(pos-float64(bar)) > 1e-9 {
+					t.Errorf("center of bar %d at %g Hz is at bar %g", bar, hz, pos)
+				}
+			}
+		})
+	}
+}